@@ -0,0 +1,92 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package testutil
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+const refNameAnnotation = "org.opencontainers.image.ref.name"
+
+// NewImage returns an image without layers with the config file, the platform defaults to linux/amd64
+func NewImage(t *testing.T, configFile *v1.ConfigFile) v1.Image {
+	if configFile.OS == "" {
+		configFile.OS = "linux"
+	}
+	if configFile.Architecture == "" {
+		configFile.Architecture = "amd64"
+	}
+	img, err := mutate.ConfigFile(empty.Image, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// NewIndex returns a multi-architecture index of the images, described by the platform of their config file
+func NewIndex(t *testing.T, images ...v1.Image) v1.ImageIndex {
+	index := v1.ImageIndex(empty.Index)
+	for _, img := range images {
+		configFile, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: configFile.OS, Architecture: configFile.Architecture, Variant: configFile.Variant},
+			},
+		})
+	}
+	return index
+}
+
+// NewLayout creates an empty OCI layout in a temporary directory of the test
+func NewLayout(t *testing.T) layout.Path {
+	p, err := layout.Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// AppendImage adds the image to the layout with the tag as ref.name annotation
+func AppendImage(t *testing.T, p layout.Path, tag string, img v1.Image) {
+	if err := p.AppendImage(img, layout.WithAnnotations(map[string]string{refNameAnnotation: tag})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AppendIndex adds the index to the layout with the tag as ref.name annotation
+func AppendIndex(t *testing.T, p layout.Path, tag string, index v1.ImageIndex) {
+	if err := p.AppendIndex(index, layout.WithAnnotations(map[string]string{refNameAnnotation: tag})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WriteImageLayout writes an OCI layout containing the image with the tag and returns its path
+func WriteImageLayout(t *testing.T, tag string, img v1.Image) string {
+	p := NewLayout(t)
+	AppendImage(t, p, tag, img)
+	return string(p)
+}
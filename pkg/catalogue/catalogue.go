@@ -0,0 +1,140 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package catalogue
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// MAX_ENTRIES is the number of images kept in the catalogue, the least recently analyzed are dropped first
+const MAX_ENTRIES = 1000
+
+// Entry is an image of the catalogue, identified as the base image of the images whose layers start with its layers
+type Entry struct {
+	Name          string   `json:"name"`
+	Digest        string   `json:"digest"`
+	DiffIDs       []string `json:"diffIDs"`
+	HistoryLength int      `json:"historyLength"`
+}
+
+// idExpr matches the image IDs, full or truncated
+var idExpr = regexp.MustCompile(`^(sha256:)?[0-9a-f]{12,64}$`)
+
+// Catalogue is the local catalogue of the images analyzed so far, stored as a JSON file
+type Catalogue struct {
+	Path string
+}
+
+// New returns the catalogue stored in the cache directory dir
+func New(dir string) Catalogue {
+	return Catalogue{Path: filepath.Join(dir, "catalogue.json")}
+}
+
+// Entries returns the images of the catalogue, the most recently analyzed last
+func (c Catalogue) Entries() ([]Entry, error) {
+	if c.Path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Record adds an analyzed image to the catalogue, images without layers are ignored as they can't be matched.
+// The image is named after the reference it was fetched from or, when analyzed by ID, after its local name if any
+func (c Catalogue) Record(name string, image *decompilerutils.Image) error {
+	if c.Path == "" || len(image.DiffIDs) == 0 || image.Digest == "" {
+		return nil
+	}
+	if image.Reference != "" {
+		name = image.Reference
+	} else if idExpr.MatchString(name) && len(image.Names) > 0 {
+		name = image.Names[0]
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		// a corrupted catalogue is replaced
+		entries = nil
+	}
+	var kept []Entry
+	for _, entry := range entries {
+		if entry.Digest != image.Digest {
+			kept = append(kept, entry)
+		}
+	}
+	kept = append(kept, Entry{
+		Name:          name,
+		Digest:        image.Digest,
+		DiffIDs:       image.DiffIDs,
+		HistoryLength: image.HistoryLength,
+	})
+	if len(kept) > MAX_ENTRIES {
+		kept = kept[len(kept)-MAX_ENTRIES:]
+	}
+	return utils.WriteJSON(c.Path, kept)
+}
+
+// Match returns the base image of an image: the image of the catalogue with the most layers whose layers and
+// history are the first ones of the image, nil if none
+func (c Catalogue) Match(image *decompilerutils.Image) (*Entry, error) {
+	if len(image.DiffIDs) == 0 {
+		return nil, nil
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var base *Entry
+	for i, entry := range entries {
+		if entry.Digest == image.Digest || len(entry.DiffIDs) == 0 || !isPrefix(entry.DiffIDs, image.DiffIDs) ||
+			entry.HistoryLength == 0 || entry.HistoryLength >= image.HistoryLength {
+			continue
+		}
+		if base == nil || len(entry.DiffIDs) > len(base.DiffIDs) ||
+			(len(entry.DiffIDs) == len(base.DiffIDs) && entry.HistoryLength > base.HistoryLength) {
+			base = &entries[i]
+		}
+	}
+	return base, nil
+}
+
+func isPrefix(prefix []string, values []string) bool {
+	if len(prefix) > len(values) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != values[i] {
+			return false
+		}
+	}
+	return true
+}
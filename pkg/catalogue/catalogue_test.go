@@ -0,0 +1,117 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package catalogue
+
+import (
+	"path/filepath"
+	"testing"
+
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+)
+
+func newImage(digest string, historyLength int, diffIDs ...string) *decompilerutils.Image {
+	return &decompilerutils.Image{
+		Digest:        digest,
+		DiffIDs:       diffIDs,
+		HistoryLength: historyLength,
+	}
+}
+
+func TestMatch(t *testing.T) {
+	images := Catalogue{Path: filepath.Join(t.TempDir(), "doa", "catalogue.json")}
+	for name, image := range map[string]*decompilerutils.Image{
+		"ubi9":         newImage("sha256:ubi9", 2, "sha256:a"),
+		"ubi9/nodejs":  newImage("sha256:nodejs", 5, "sha256:a", "sha256:b"),
+		"ubi9/python":  newImage("sha256:python", 5, "sha256:a", "sha256:c"),
+		"empty":        newImage("sha256:empty", 1),
+		"ubi9/nodejs2": newImage("sha256:nodejs2", 9, "sha256:a", "sha256:b", "sha256:d", "sha256:e"),
+	} {
+		if err := images.Record(name, image); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := images.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected the images without layers not to be recorded but the entries were %+v", entries)
+	}
+
+	for _, test := range []struct {
+		image *decompilerutils.Image
+		base  string
+	}{
+		{newImage("sha256:app", 7, "sha256:a", "sha256:b", "sha256:d"), "ubi9/nodejs"},
+		{newImage("sha256:app", 3, "sha256:a", "sha256:f"), "ubi9"},
+		{newImage("sha256:app", 3, "sha256:f"), ""},
+		{newImage("sha256:nodejs", 5, "sha256:a", "sha256:b"), "ubi9"},
+		// the base image can't have more history entries than the image
+		{newImage("sha256:app", 2, "sha256:a", "sha256:b"), ""},
+	} {
+		base, err := images.Match(test.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (base == nil && test.base != "") || (base != nil && base.Name != test.base) {
+			t.Errorf("Expected the base image of %v to be %q but it was %+v", test.image.DiffIDs, test.base, base)
+		}
+	}
+}
+
+func TestRecordReplacesImage(t *testing.T) {
+	images := Catalogue{Path: filepath.Join(t.TempDir(), "catalogue.json")}
+	image := newImage("sha256:ubi9", 2, "sha256:a")
+	if err := images.Record("3c8f7a4b9d2e", image); err != nil {
+		t.Fatal(err)
+	}
+	image.Reference = "registry.access.redhat.com/ubi9/ubi:latest"
+	if err := images.Record("ubi9", image); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := images.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != image.Reference {
+		t.Errorf("Expected a single entry named after the image reference but they were %+v", entries)
+	}
+}
+
+func TestRecordNamesImageID(t *testing.T) {
+	images := Catalogue{Path: filepath.Join(t.TempDir(), "catalogue.json")}
+	image := newImage("sha256:ubi9", 2, "sha256:a")
+	image.Names = []string{"localhost/ubi9:latest"}
+	if err := images.Record("3c8f7a4b9d2e", image); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := images.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "localhost/ubi9:latest" {
+		t.Errorf("Expected the image analyzed by ID to be named after its local name but the entries were %+v", entries)
+	}
+}
+
+func TestMissingCatalogue(t *testing.T) {
+	images := Catalogue{Path: filepath.Join(t.TempDir(), "catalogue.json")}
+	if base, err := images.Match(newImage("sha256:app", 2, "sha256:a")); base != nil || err != nil {
+		t.Errorf("Expected no base image and no error without catalogue but they were %+v and %v", base, err)
+	}
+}
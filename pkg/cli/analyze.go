@@ -19,11 +19,17 @@
  package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	analyzer "github.com/redhat-developer/docker-openshift-analyzer/pkg/command"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/cache"
 	"github.com/spf13/cobra"
 )
 
@@ -40,11 +46,15 @@ func NewCmdAnalyze() *cobra.Command {
 		"file", "f", "", "Container file to analyze",
 	)
 	analyzeCmd.PersistentFlags().StringP(
-		"image", "i", "", "Image name to analyze",
+		"image", "i", "", "Image name to analyze, use oci:/path/to/layout[:tag] for an OCI layout directory and docker-archive:/path/to/archive.tar[:name:tag] or oci-archive:/path/to/archive.tar[:tag] for an archive",
 	)
 	analyzeCmd.PersistentFlags().StringP(
 		"output", "o", "", "Specify output format, supported format: json",
 	)
+	analyzeCmd.PersistentFlags().Bool(
+		"all-platforms", false, "Analyze all the platforms of a multi-architecture image and report their differences",
+	)
+	addImageFlags(analyzeCmd)
 	return analyzeCmd
 }
 
@@ -57,20 +67,132 @@ func doAnalyze(cmd *cobra.Command, args []string) {
 	}
 
 	outputFunc := PrintPrettifyOutput
+	printInfo := false
 	out := cmd.Flag("output")
 	if out.Value.String() != "" && !strings.EqualFold(out.Value.String(), "json") {
 		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("unknown value '%s' for flag %s, type --help for a list of all flags\n", out.Value.String(), out.Name))
 	} else if strings.EqualFold(out.Value.String(), "json") {
 		outputFunc = PrintPrettifyJsonOutput
+	} else if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		outputFunc = PrintVerboseOutput
+		printInfo = true
 	}
 
+	options := imageOptions(cmd)
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	var results []analyzer.Result
+	var info []analyzer.Info
 	if containerfile.Value.String() != "" {
-		outputFunc(analyzer.AnalyzePath(containerfile.Value.String()))
-	} else if image.Value.String() != "" {
-		outputFunc(analyzer.AnalyzeImage(image.Value.String()))
+		results, info = analyzer.AnalyzePath(ctx, containerfile.Value.String(), options)
+	} else if allPlatforms, _ := cmd.Flags().GetBool("all-platforms"); allPlatforms {
+		results, info = analyzer.AnalyzeImagePlatforms(ctx, image.Value.String(), options)
+	} else {
+		results, info = analyzer.AnalyzeImage(ctx, image.Value.String(), options)
+	}
+	outputFunc(results)
+	if printInfo {
+		PrintInfoOutput(info)
 	}
 }
 
+// addImageFlags adds the flags setting where and how images are looked up
+func addImageFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(
+		"connection", "", "Name of the Podman connection to use to look up images",
+	)
+	cmd.PersistentFlags().String(
+		"authfile", "", "Path of the registry credentials file, defaults to the one used by podman login ($REGISTRY_AUTH_FILE or ${XDG_RUNTIME_DIR}/containers/auth.json)",
+	)
+	cmd.PersistentFlags().String(
+		"registries-conf", "", "Path of the registries.conf file used for the short names, mirrors and blocked registries",
+	)
+	cmd.PersistentFlags().String(
+		"creds", "", "Credentials (username[:password]) to use for the registries",
+	)
+	cmd.PersistentFlags().String(
+		"cert-dir", "", "Directory holding the certificates (*.crt, *.cert and *.key) used for the registries, instead of their certs.d directory",
+	)
+	cmd.PersistentFlags().Bool(
+		"tls-verify", true, "Require HTTPS and verify the certificates of the registries",
+	)
+	cmd.PersistentFlags().String(
+		"platform", "", fmt.Sprintf("Platform (os/arch[/variant]) to select in multi-architecture images, defaults to %s", decompiler.DefaultPlatform()),
+	)
+	cmd.PersistentFlags().BoolP(
+		"verbose", "v", false, "Print why images could not be found by each provider and how the images were analyzed",
+	)
+	cmd.PersistentFlags().String(
+		"source", decompiler.SourceAuto, fmt.Sprintf("Where to look up images, supported sources: %s", strings.Join(decompiler.Sources, ", ")),
+	)
+	cmd.PersistentFlags().Bool(
+		"no-cache", false, "Fetch and decompile again the images from the registries instead of using the cache",
+	)
+	cmd.PersistentFlags().Bool(
+		"offline", false, "Only look up the local images and the images cached from registries, no registry is contacted",
+	)
+	cmd.PersistentFlags().Duration(
+		"timeout", 0, "Maximum duration of the analysis (e.g. 30s, 2m), the results found so far are reported once it is reached",
+	)
+	cmd.PersistentFlags().Duration(
+		"cache-ttl", cache.DEFAULT_TTL, "How long the digest a tag was resolved to is reused before contacting the registry again",
+	)
+	cmd.PersistentFlags().String(
+		"cache-dir", "", "Directory of the cache and of the catalogue of analyzed images, defaults to $XDG_CACHE_HOME/doa",
+	)
+}
+
+// imageOptions returns the options to look up images set by the flags added by addImageFlags
+func imageOptions(cmd *cobra.Command) decompiler.Options {
+	source := cmd.Flag("source")
+	if !isSupportedSource(source.Value.String()) {
+		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("unknown value '%s' for flag %s, type --help for a list of all flags\n", source.Value.String(), source.Name))
+	}
+
+	options := decompiler.Options{
+		Connection:     cmd.Flag("connection").Value.String(),
+		Source:         cmd.Flag("source").Value.String(),
+		AuthFile:       cmd.Flag("authfile").Value.String(),
+		RegistriesConf: cmd.Flag("registries-conf").Value.String(),
+		Credentials:    cmd.Flag("creds").Value.String(),
+		CertDir:        cmd.Flag("cert-dir").Value.String(),
+		Platform:       cmd.Flag("platform").Value.String(),
+	}
+	if tlsVerify, _ := cmd.Flags().GetBool("tls-verify"); !tlsVerify {
+		options.InsecureSkipTLSVerify = true
+	}
+	options.NoCache, _ = cmd.Flags().GetBool("no-cache")
+	options.CacheTTL, _ = cmd.Flags().GetDuration("cache-ttl")
+	options.Offline, _ = cmd.Flags().GetBool("offline")
+	options.CacheDir, _ = cmd.Flags().GetString("cache-dir")
+	return options
+}
+
+// commandContext returns the context of a command, cancelled when doa is interrupted or terminated and,
+// if set with --timeout, when the timeout is reached
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil || timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+func isSupportedSource(source string) bool {
+	for _, supported := range decompiler.Sources {
+		if source == supported {
+			return true
+		}
+	}
+	return false
+}
+
 func PrintNoArgsWarningMessage(command string) {
 	fmt.Printf(`
 No arg received. Did you forget to add the Containerfile or project path to analyze?
@@ -96,3 +218,25 @@ func PrintPrettifyOutput(results []analyzer.Result) {
 		fmt.Printf("%d - %s (%s): %s\n\n", i+1, sug.Name, sug.Severity, sug.Description)
 	}
 }
+
+func PrintVerboseOutput(results []analyzer.Result) {
+	for i, sug := range results {
+		fmt.Printf("%d - %s (%s): %s\n", i+1, sug.Name, sug.Severity, sug.Description)
+		for _, diagnostic := range sug.Diagnostics {
+			fmt.Printf("    %s\n", diagnostic)
+		}
+		fmt.Println()
+	}
+}
+
+// PrintInfoOutput prints how the analysis was done: where the images were found, the syntax frontend, the base
+// images not analyzed and the lineage
+func PrintInfoOutput(info []analyzer.Info) {
+	for _, entry := range info {
+		fmt.Printf("%s: %s\n", entry.Name, entry.Description)
+		for _, diagnostic := range entry.Diagnostics {
+			fmt.Printf("    %s\n", diagnostic)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,78 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/cache"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCache() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of the images decompiled from registries",
+		Args:  cobra.NoArgs,
+	}
+	pruneCmd := &cobra.Command{
+		Use:     "prune",
+		Short:   "Remove the old entries of the cache",
+		Long:    "Remove the expired tag resolutions and the images and findings unused for longer than --older-than from the cache of the images decompiled from registries. The catalogue of analyzed images is kept.",
+		Args:    cobra.NoArgs,
+		Run:     doCachePrune,
+		Example: `  doa cache prune --older-than 168h`,
+	}
+	pruneCmd.Flags().Duration(
+		"older-than", 30*24*time.Hour, "Remove the images and findings unused for longer than this duration",
+	)
+	pruneCmd.Flags().Bool(
+		"all", false, "Remove all the entries of the cache",
+	)
+	pruneCmd.Flags().Duration(
+		"cache-ttl", cache.DEFAULT_TTL, "How long the digest a tag was resolved to is reused, the older resolutions are removed",
+	)
+	pruneCmd.Flags().String(
+		"cache-dir", "", "Directory of the cache, defaults to $XDG_CACHE_HOME/doa",
+	)
+	cacheCmd.AddCommand(pruneCmd)
+	return cacheCmd
+}
+
+func doCachePrune(cmd *cobra.Command, args []string) {
+	ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+	c := cache.Default(ttl)
+	if dir, _ := cmd.Flags().GetString("cache-dir"); dir != "" {
+		c = cache.New(dir, ttl)
+	}
+	if c == nil {
+		RedirectErrorStringToStdErrAndExit("unable to find the user cache directory")
+	}
+	olderThan, _ := cmd.Flags().GetDuration("older-than")
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		olderThan = 0
+	} else if olderThan <= 0 {
+		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("invalid value '%s' for flag older-than, it must be positive\n", olderThan))
+	}
+	removed, err := c.Prune(olderThan)
+	if err != nil {
+		RedirectErrorStringToStdErrAndExit(err.Error())
+	}
+	fmt.Printf("%d entries removed from %s\n", removed, c.Dir)
+}
@@ -65,6 +65,10 @@ func DockerOpenShiftAnalyzerCommands() *cobra.Command {
 
 	rootCmdList := append([]*cobra.Command{},
 		NewCmdAnalyze(),
+		NewCmdCache(),
+		NewCmdDecompile(),
+		NewCmdDoctor(),
+		NewCmdLineage(),
 	)
 
 	rootCmd.AddCommand(rootCmdList...)
@@ -0,0 +1,92 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	analyzer "github.com/redhat-developer/docker-openshift-analyzer/pkg/command"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDecompile() *cobra.Command {
+	decompileCmd := &cobra.Command{
+		Use:     "decompile",
+		Short:   "Print the Containerfile rebuilt from an image",
+		Long:    "Print the Containerfile rebuilt from the history and the configuration of an image, with the history entry, layer size and build arguments of each instruction as comments.",
+		Args:    cobra.MaximumNArgs(0),
+		Run:     doDecompile,
+		Example: `  doa decompile -i registry.access.redhat.com/ubi9/ubi:latest`,
+	}
+	decompileCmd.PersistentFlags().StringP(
+		"image", "i", "", "Image name to decompile, use oci:/path/to/layout[:tag] for an OCI layout directory and docker-archive:/path/to/archive.tar[:name:tag] or oci-archive:/path/to/archive.tar[:tag] for an archive",
+	)
+	decompileCmd.PersistentFlags().StringP(
+		"output", "o", "", "Specify output format, supported format: json",
+	)
+	addImageFlags(decompileCmd)
+	return decompileCmd
+}
+
+func doDecompile(cmd *cobra.Command, args []string) {
+	image := cmd.Flag("image")
+	if image.Value.String() == "" {
+		RedirectErrorStringToStdErrAndExit("no image received, set the image to decompile with --image")
+	}
+
+	outputFunc := PrintDecompiledOutput
+	out := cmd.Flag("output")
+	if out.Value.String() != "" && !strings.EqualFold(out.Value.String(), "json") {
+		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("unknown value '%s' for flag %s, type --help for a list of all flags\n", out.Value.String(), out.Name))
+	} else if strings.EqualFold(out.Value.String(), "json") {
+		outputFunc = PrintDecompiledJsonOutput
+	}
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	decompiled, err := decompiler.Decompile(ctx, image.Value.String(), imageOptions(cmd))
+	if err != nil {
+		message := err.Error()
+		if ctx.Err() != nil {
+			message += " - " + ctx.Err().Error()
+		}
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			for _, diagnostic := range analyzer.GetDiagnostics(err) {
+				message += "\n    " + diagnostic
+			}
+		}
+		RedirectErrorStringToStdErrAndExit(message)
+	}
+	outputFunc(decompiler.NewDecompiledImage(image.Value.String(), decompiled))
+}
+
+func PrintDecompiledJsonOutput(decompiled decompiler.DecompiledImage) {
+	var bytes []byte
+	var err error
+	if bytes, err = json.MarshalIndent(decompiled, "", "    "); err != nil {
+		fmt.Println("error while converting output to json. Please try again without the output (--o) flag")
+	}
+	fmt.Println(string(bytes))
+}
+
+func PrintDecompiledOutput(decompiled decompiler.DecompiledImage) {
+	fmt.Print(decompiled.Containerfile())
+}
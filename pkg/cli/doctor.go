@@ -0,0 +1,81 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDoctor() *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Check which image providers can be used",
+		Long:    "Check which image providers can be used to look up images: Podman and Docker services, local containers-storage, registries configuration and credentials.",
+		Args:    cobra.MaximumNArgs(0),
+		Run:     doDoctor,
+		Example: `  doa doctor -o json`,
+	}
+	doctorCmd.PersistentFlags().StringP(
+		"output", "o", "", "Specify output format, supported format: json",
+	)
+	addImageFlags(doctorCmd)
+	return doctorCmd
+}
+
+func doDoctor(cmd *cobra.Command, args []string) {
+	outputFunc := PrintDoctorOutput
+	out := cmd.Flag("output")
+	if out.Value.String() != "" && !strings.EqualFold(out.Value.String(), "json") {
+		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("unknown value '%s' for flag %s, type --help for a list of all flags\n", out.Value.String(), out.Name))
+	} else if strings.EqualFold(out.Value.String(), "json") {
+		outputFunc = PrintDoctorJsonOutput
+	}
+
+	options := imageOptions(cmd)
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	statuses, err := decompiler.Doctor(ctx, options)
+	if err != nil {
+		RedirectErrorStringToStdErrAndExit(err.Error())
+	}
+	outputFunc(statuses)
+}
+
+func PrintDoctorJsonOutput(statuses []decompiler.ProviderStatus) {
+	var bytes []byte
+	var err error
+	if bytes, err = json.MarshalIndent(statuses, "", "    "); err != nil {
+		fmt.Println("error while converting output to json. Please try again without the output (--o) flag")
+	}
+	fmt.Println(string(bytes))
+}
+
+func PrintDoctorOutput(statuses []decompiler.ProviderStatus) {
+	for _, status := range statuses {
+		usable := "usable"
+		if !status.Usable {
+			usable = "not usable"
+		}
+		fmt.Printf("%s (%s): %s\n", status.Provider, usable, status.Details)
+	}
+}
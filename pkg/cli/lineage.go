@@ -0,0 +1,129 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	analyzer "github.com/redhat-developer/docker-openshift-analyzer/pkg/command"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdLineage() *cobra.Command {
+	lineageCmd := &cobra.Command{
+		Use:     "lineage",
+		Short:   "List the base images of a Containerfile or an image",
+		Long:    "List the base images analyzed for a Containerfile or an image (name, digest, provider and number of instructions) with the findings coming from each of them.",
+		Args:    cobra.MaximumNArgs(0),
+		Run:     doLineage,
+		Example: `  doa lineage -f /your/local/project/path[/Containerfile_name]`,
+	}
+	lineageCmd.PersistentFlags().StringP(
+		"file", "f", "", "Container file whose base images are listed",
+	)
+	lineageCmd.PersistentFlags().StringP(
+		"image", "i", "", "Image name whose base images are listed, use oci:/path/to/layout[:tag] for an OCI layout directory and docker-archive:/path/to/archive.tar[:name:tag] or oci-archive:/path/to/archive.tar[:tag] for an archive",
+	)
+	lineageCmd.PersistentFlags().StringP(
+		"output", "o", "", "Specify output format, supported format: json",
+	)
+	addImageFlags(lineageCmd)
+	return lineageCmd
+}
+
+func doLineage(cmd *cobra.Command, args []string) {
+	containerfile := cmd.Flag("file")
+	image := cmd.Flag("image")
+	if containerfile.Value.String() == "" && image.Value.String() == "" {
+		PrintNoArgsWarningMessage(cmd.Name())
+		return
+	}
+
+	outputFunc := PrintLineageOutput
+	out := cmd.Flag("output")
+	if out.Value.String() != "" && !strings.EqualFold(out.Value.String(), "json") {
+		RedirectErrorStringToStdErrAndExit(fmt.Sprintf("unknown value '%s' for flag %s, type --help for a list of all flags\n", out.Value.String(), out.Name))
+	} else if strings.EqualFold(out.Value.String(), "json") {
+		outputFunc = PrintLineageJsonOutput
+	}
+
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	var lineage []analyzer.Ancestor
+	var results []analyzer.Result
+	var info []analyzer.Info
+	if containerfile.Value.String() != "" {
+		lineage, results, info = analyzer.PathLineage(ctx, containerfile.Value.String(), imageOptions(cmd))
+	} else {
+		lineage, results, info = analyzer.ImageLineage(ctx, image.Value.String(), imageOptions(cmd))
+	}
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	printLineageErrors(results, info, verbose)
+	outputFunc(lineage)
+}
+
+// printLineageErrors prints the images which could not be analyzed to the standard error
+func printLineageErrors(results []analyzer.Result, info []analyzer.Info, verbose bool) {
+	for _, result := range results {
+		if result.Name != "Analyze error" && result.Name != "File not found" && result.Name != "Parse error" {
+			continue
+		}
+		printLineageError(result.Description, result.Diagnostics, verbose)
+	}
+	for _, entry := range info {
+		if entry.Name == "Base image not analyzed" {
+			printLineageError(entry.Description, entry.Diagnostics, verbose)
+		}
+	}
+}
+
+func printLineageError(description string, diagnostics []string, verbose bool) {
+	fmt.Fprintln(os.Stderr, description)
+	if verbose {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintf(os.Stderr, "    %s\n", diagnostic)
+		}
+	}
+}
+
+func PrintLineageJsonOutput(lineage []analyzer.Ancestor) {
+	if lineage == nil {
+		lineage = []analyzer.Ancestor{}
+	}
+	var bytes []byte
+	var err error
+	if bytes, err = json.MarshalIndent(lineage, "", "    "); err != nil {
+		fmt.Println("error while converting output to json. Please try again without the output (--o) flag")
+	}
+	fmt.Println(string(bytes))
+}
+
+func PrintLineageOutput(lineage []analyzer.Ancestor) {
+	if len(lineage) == 0 {
+		fmt.Println("No base image analyzed")
+	}
+	for _, ancestor := range lineage {
+		fmt.Println(ancestor.String())
+		for _, finding := range ancestor.Findings {
+			fmt.Printf("    - %s\n", finding)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+type Add struct{}
+
+type addResultKeyType struct{}
+
+var addResultKey addResultKeyType
+
+var remoteURLExpr = regexp.MustCompile(`^https?://`)
+var archiveExpr = regexp.MustCompile(`(?i)\.(tar|tar\.gz|tgz|tar\.bz2|tbz2?|tar\.xz|txz|tar\.zst)$`)
+
+func (a Add) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
+	// the last argument is the destination
+	if node.Next == nil || !remoteURLExpr.MatchString(node.Value) {
+		return ctx
+	}
+	var results []Result
+	if !hasChecksumFlag(GetInstruction(ctx)) {
+		results = append(results, Result{
+			Name:     "Remote content added without checksum",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf(`ADD %s %s pulls unverified content into the image which could be rejected by OpenShift image policies. 
+		Use ADD --checksum=sha256:<digest> %s or download it in a RUN instruction and verify it with sha256sum -c`, node.Value, GenerateErrorLocation(source, line), node.Value),
+		})
+	}
+	if archiveExpr.MatchString(strings.SplitN(node.Value, "?", 2)[0]) {
+		results = append(results, Result{
+			Name:     "Remote archive not extracted",
+			Status:   StatusFailed,
+			Severity: SeverityLow,
+			Description: fmt.Sprintf(`ADD %s %s copies the archive as is, archives from remote URLs are not extracted. 
+		Download and extract it in a RUN instruction if its content is expected in the image`, node.Value, GenerateErrorLocation(source, line)),
+		})
+	}
+	return appendResults(ctx, addResultKey, results)
+}
+
+func (a Add) PostProcess(ctx context.Context) []Result {
+	result := ctx.Value(addResultKey)
+	if result == nil {
+		return nil
+	}
+	return result.([]Result)
+}
+
+func hasChecksumFlag(instruction *parser.Node) bool {
+	if instruction == nil {
+		return false
+	}
+	for _, flag := range instruction.Flags {
+		if strings.HasPrefix(flag, "--checksum=") {
+			return true
+		}
+	}
+	return false
+}
@@ -18,8 +18,11 @@
  package command
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -51,6 +54,20 @@ type Result struct {
 	Status      ResultStatus   `json:"status"`
 	Severity    ResultSeverity `json:"severity"`
 	Description string         `json:"description"`
+	// Diagnostics explain why an image could not be analyzed, e.g. the error of each provider tried
+	Diagnostics []string `json:"diagnostics,omitempty"`
+	// Source is the parent image whose instructions the result comes from, empty if it comes from the analyzed
+	// Containerfile or image
+	Source string `json:"source,omitempty"`
+}
+
+// Info describes how the analysis was done, e.g. where the image was found or the base images not analyzed.
+// It is not a finding
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Diagnostics explain why an image could not be analyzed, e.g. the error of each provider tried
+	Diagnostics []string `json:"diagnostics,omitempty"`
 }
 
 type Line struct {
@@ -58,19 +75,38 @@ type Line struct {
 	End   int
 }
 
+type instructionKeyType struct{}
+type decompilerOptionsKeyType struct{}
+type infoKeyType struct{}
+
+var instructionKey instructionKeyType
+var decompilerOptionsKey decompilerOptionsKeyType
+var infoKey infoKeyType
+
 type Command interface {
 	Analyze(context.Context, *parser.Node, utils.Source, Line) context.Context
 	PostProcess(ctx context.Context) []Result
 }
 
 var commandHandlers = map[string]Command{
-	utils.EXPOSE_INSTRUCTION: Expose{},
-	utils.FROM_INSTRUCTION:   From{},
-	utils.RUN_INSTRUCTION:    Run{},
-	utils.USER_INSTRUCTION:   User{},
+	utils.ADD_INSTRUCTION:        Add{},
+	utils.CMD_INSTRUCTION:        Entrypoint{},
+	utils.ENTRYPOINT_INSTRUCTION: Entrypoint{},
+	utils.ENV_INSTRUCTION:        Env{},
+	utils.EXPOSE_INSTRUCTION:     Expose{},
+	utils.FROM_INSTRUCTION:       From{},
+	utils.RUN_INSTRUCTION:        Run{},
+	utils.USER_INSTRUCTION:       User{},
 }
 
-func AnalyzePath(path string) []Result {
+// AnalyzePath analyzes the Containerfile at path, or in the path directory. The base images which are not decompiled
+// before the context is done are reported as analyze errors with the results found so far
+func AnalyzePath(ctx context.Context, path string, options decompiler.Options) ([]Result, []Info) {
+	results, info, lineage := analyzePath(ctx, path, options)
+	return results, append(info, lineageInfo(lineage)...)
+}
+
+func analyzePath(ctx context.Context, path string, options decompiler.Options) ([]Result, []Info, []Ancestor) {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return []Result{
@@ -80,7 +116,7 @@ func AnalyzePath(path string) []Result {
 				Severity:    SeverityCritical,
 				Description: fmt.Sprintf("unable to analyze %s - error %s", path, err),
 			},
-		}
+		}, nil, nil
 	}
 
 	if fileInfo.IsDir() {
@@ -99,35 +135,78 @@ func AnalyzePath(path string) []Result {
 				Severity:    SeverityCritical,
 				Description: fmt.Sprintf("unable to open %s - error %s", path, err),
 			},
-		}
+		}, nil, nil
 	}
 	defer file.Close()
 
-	return AnalyzeFile(file)
+	return analyzeFile(ctx, file, options)
+}
+
+// AnalyzeImage analyzes the instructions rebuilt from an image, an analyze error is returned if the image is not
+// decompiled before the context is done
+func AnalyzeImage(ctx context.Context, image string, options decompiler.Options) ([]Result, []Info) {
+	results, info, lineage := analyzeImage(ctx, image, options)
+	return results, append(info, lineageInfo(lineage)...)
 }
 
-func AnalyzeImage(image string) []Result {
-	node, err := decompiler.Decompile(image)
+func analyzeImage(ctx context.Context, image string, options decompiler.Options) ([]Result, []Info, []Ancestor) {
+	decompiledImage, err := decompiler.Decompile(ctx, image, options)
 	if err != nil {
 		return []Result{
 			{
 				Name:        "Analyze error",
 				Status:      StatusFailed,
 				Severity:    SeverityCritical,
-				Description: fmt.Sprintf("unable to analyze %s - error %s", image, err),
+				Description: fmt.Sprintf("unable to analyze %s - error %s", image, decompileError(ctx, err)),
+				Diagnostics: GetDiagnostics(err),
 			},
-		}
+		}, nil, nil
 	}
-	ctx := context.Background()
-	suggestions, _ := AnalyzeNodeFromSource(ctx, node, utils.Source{
+	return analyzeDecompiledImage(ctx, image, decompiledImage, options)
+}
+
+func analyzeDecompiledImage(ctx context.Context, image string, decompiledImage *decompiler.Image, options decompiler.Options) ([]Result, []Info, []Ancestor) {
+	ctx = WithDecompilerOptions(ctx, options)
+	suggestions, ctx := analyzeDecompiledNode(ctx, image, decompiledImage, utils.Source{
 		Name: "",
 		Type: utils.Image,
 	})
-	return suggestions
+	description := fmt.Sprintf("image %s found by the %s provider with digest %s", image, decompiledImage.Provider, decompiledImage.Digest)
+	if decompiledImage.Reference != "" {
+		description = fmt.Sprintf("image %s found by the %s provider at %s with digest %s", image, decompiledImage.Provider, decompiledImage.Reference, decompiledImage.Digest)
+	}
+	if decompiledImage.Platform != "" {
+		description += fmt.Sprintf(" for platform %s", decompiledImage.Platform)
+	}
+	info := append([]Info{
+		{
+			Name:        "Image source",
+			Description: description,
+		},
+	}, GetInfo(ctx)...)
+	return suggestions, info, attributeFindings(GetLineage(ctx), suggestions)
+}
+
+func AnalyzeFile(ctx context.Context, file *os.File, options decompiler.Options) ([]Result, []Info) {
+	results, info, lineage := analyzeFile(ctx, file, options)
+	return results, append(info, lineageInfo(lineage)...)
 }
 
-func AnalyzeFile(file *os.File) []Result {
-	res, err := parser.Parse(file)
+func analyzeFile(ctx context.Context, file *os.File, options decompiler.Options) ([]Result, []Info, []Ancestor) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return []Result{
+			{
+				Name:        "File not found",
+				Status:      StatusFailed,
+				Severity:    SeverityCritical,
+				Description: fmt.Sprintf("unable to read %s - error %s", file.Name(), err),
+			},
+		}, nil, nil
+	}
+
+	// the parser honours the escape directive, the syntax one is only reported
+	res, err := parser.Parse(bytes.NewReader(content))
 	if err != nil {
 		return []Result{
 			{
@@ -136,16 +215,17 @@ func AnalyzeFile(file *os.File) []Result {
 				Severity:    SeverityCritical,
 				Description: fmt.Sprintf("unable to analyze the Containerfile. Error when parsing %s : %s", file.Name(), err.Error()),
 			},
-		}
+		}, nil, nil
 	}
 
-	ctx := context.Background()
+	ctx = WithDecompilerOptions(ctx, options)
 
-	suggestions, _ := AnalyzeNodeFromSource(ctx, res.AST, utils.Source{
+	suggestions, ctx := AnalyzeNodeFromSource(ctx, res.AST, utils.Source{
 		Name: "",
 		Type: utils.Image,
 	})
-	return suggestions
+	directiveResults, info := AnalyzeSyntaxDirective(content)
+	return append(directiveResults, suggestions...), append(info, GetInfo(ctx)...), attributeFindings(GetLineage(ctx), suggestions)
 }
 
 func AnalyzeNodeFromSource(ctx context.Context, node *parser.Node, source utils.Source) ([]Result, context.Context) {
@@ -159,6 +239,7 @@ func AnalyzeNodeFromSource(ctx context.Context, node *parser.Node, source utils.
 		}
 		handler := commandHandlers[strings.ToUpper(child.Value+" ")]
 		if handler != nil {
+			ctx = context.WithValue(ctx, instructionKey, child)
 			for n := child.Next; n != nil; n = n.Next {
 				if n.Value == "" {
 					suggestions = append(suggestions, Result{
@@ -166,22 +247,92 @@ func AnalyzeNodeFromSource(ctx context.Context, node *parser.Node, source utils.
 						Status:      StatusFailed,
 						Severity:    SeverityMedium,
 						Description: fmt.Sprintf("%s %s has an empty value", child.Value, GenerateErrorLocation(source, line)),
+						Source:      parentName(source),
 					})
 
 				} else {
-					ctx = handler.Analyze(ctx, n, source, line)
+					ctx = setResultsSource(handler.Analyze(ctx, n, source, line), source)
 				}
 			}
 		}
 	}
+	// the same handler can be registered for several instructions (e.g. CMD and ENTRYPOINT)
+	processed := map[Command]bool{}
 	for key, _ := range commandHandlers {
 		handler := commandHandlers[key]
+		if processed[handler] {
+			continue
+		}
+		processed[handler] = true
 
 		suggestions = append(suggestions, handler.PostProcess(ctx)...)
 	}
 	return suggestions, ctx
 }
 
+// decompileError returns the reason why an image was not decompiled, the deadline or the cancellation of the analysis
+// if it was interrupted
+func decompileError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// GetDiagnostics returns the error of each provider which failed to decompile an image
+func GetDiagnostics(err error) []string {
+	var resolveErr *decompiler.ResolveError
+	if !errors.As(err, &resolveErr) {
+		return nil
+	}
+	diagnostics := []string{}
+	for _, providerErr := range resolveErr.Errors {
+		diagnostics = append(diagnostics, providerErr.Error())
+	}
+	return diagnostics
+}
+
+// WithDecompilerOptions sets the options used to decompile the parent images
+func WithDecompilerOptions(ctx context.Context, options decompiler.Options) context.Context {
+	return context.WithValue(ctx, decompilerOptionsKey, options)
+}
+
+func GetDecompilerOptions(ctx context.Context) decompiler.Options {
+	options := ctx.Value(decompilerOptionsKey)
+	if options == nil {
+		return decompiler.Options{}
+	}
+	return options.(decompiler.Options)
+}
+
+// GetInstruction returns the instruction node (with its flags and heredocs) of the argument being analyzed
+func GetInstruction(ctx context.Context) *parser.Node {
+	instruction := ctx.Value(instructionKey)
+	if instruction == nil {
+		return nil
+	}
+	return instruction.(*parser.Node)
+}
+
+// appendInfo adds the info to the one already stored in the context
+func appendInfo(ctx context.Context, info ...Info) context.Context {
+	return context.WithValue(ctx, infoKey, append(append([]Info{}, GetInfo(ctx)...), info...))
+}
+
+// GetInfo returns the info about the analysis stored in the context so far, e.g. the base images not analyzed
+func GetInfo(ctx context.Context) []Info {
+	info, _ := ctx.Value(infoKey).([]Info)
+	return info
+}
+
+// appendResults adds the results to the ones already stored in the context under the key
+func appendResults(ctx context.Context, key interface{}, results []Result) context.Context {
+	if previous, ok := ctx.Value(key).([]Result); ok {
+		results = append(append([]Result{}, previous...), results...)
+	}
+	return context.WithValue(ctx, key, results)
+}
+
 func IsCommand(text string, command string) bool {
 	return strings.Contains(text, command)
 }
@@ -18,25 +18,125 @@
 
  package command
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
 
 func TestCheckNginx(t *testing.T) {
 	for _, tag := range []string{"1.25.0", "1.25.1", "1.25.2", "1.25.3"} {
 		t.Run(tag, func(t *testing.T) {
-			AnalyzeImage("docker.io/nginx:" + tag)
+			AnalyzeImage(context.Background(), "docker.io/nginx:"+tag, decompiler.Options{})
 		})
 	}
 }
 
 func TestFromScratch(t *testing.T) {
-	errors := AnalyzePath("resources/Containerfile.fromscratch")
+	errors, _ := AnalyzePath(context.Background(), "resources/Containerfile.fromscratch", decompiler.Options{})
 	if len(errors) != 1 {
 		t.Error("Image with FROM scratch returns errors")
 	}
 }
 func TestFromNginxWithUser(t *testing.T) {
-	errors := AnalyzePath("resources/Containerfile.fromnginxwithuser")
-	if len(errors) != 1 {
-		t.Error("Image with FROM nginx with USER returns errors")
+	nginx := writeImageLayout(t, &v1.ConfigFile{
+		History: []v1.History{
+			{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /"},
+			{CreatedBy: "/bin/sh -c groupadd --system nginx && useradd --system -g nginx nginx"},
+		},
+	})
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	if err := os.WriteFile(containerfile, []byte("FROM "+nginx+"\nUSER nginx\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	errors, info := AnalyzePath(context.Background(), containerfile, decompiler.Options{CacheDir: t.TempDir()})
+	if len(errors) != 0 {
+		t.Errorf("Image with FROM nginx with USER returns errors %v", errors)
+	}
+	if lineage := findInfo(info, "Base image lineage"); len(lineage) != 1 || !strings.Contains(lineage[0].Description, nginx) {
+		t.Errorf("Expected the base image in the lineage info but it was %v", info)
+	}
+}
+
+func TestOfflineBaseImage(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	if err := os.WriteFile(containerfile, []byte("FROM registry.example.com/app:1.0\nUSER 1001\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	results, info := AnalyzePath(context.Background(), containerfile, decompiler.Options{Offline: true})
+	if len(results) != 0 {
+		t.Errorf("Expected no finding in offline mode but they were %v", results)
+	}
+	notAnalyzed := findInfo(info, "Base image not analyzed")
+	if len(notAnalyzed) != 1 || notAnalyzed[0].Description != "base image registry.example.com/app:1.0 not analyzed (offline)" {
+		t.Errorf("Expected the base image to be reported as not analyzed but the info was %v", info)
+	}
+}
+
+func TestInterruptedAnalysis(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	if err := os.WriteFile(containerfile, []byte("FROM registry.example.com/app:1.0\nUSER root\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, _ := AnalyzePath(ctx, containerfile, decompiler.Options{})
+	errors := findResults(results, "Analyze error")
+	if len(errors) != 1 || !strings.HasSuffix(errors[0].Description, context.Canceled.Error()) {
+		t.Errorf("Expected the base image not to be analyzed once the analysis is cancelled but the results were %v", results)
+	}
+	if len(findResults(results, "User set to root")) != 1 {
+		t.Errorf("Expected the rest of the Containerfile to be analyzed but the results were %v", results)
+	}
+}
+
+func TestAnalyzeErrorDiagnostics(t *testing.T) {
+	results, _ := AnalyzeImage(context.Background(), "oci:/nonexistent/layout:1.0", decompiler.Options{})
+	if len(results) != 1 || results[0].Name != "Analyze error" {
+		t.Fatalf("Expected an analyze error but they were %v", results)
+	}
+	if len(results[0].Diagnostics) != 1 || !strings.HasPrefix(results[0].Diagnostics[0], "oci-layout: ") {
+		t.Errorf("Expected the diagnostic of the oci-layout provider but they were %v", results[0].Diagnostics)
+	}
+}
+
+func analyzeContainerfile(t *testing.T, content string) []Result {
+	res, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unable to parse Containerfile: %s", err)
+	}
+	suggestions, _ := AnalyzeNodeFromSource(context.Background(), res.AST, utils.Source{
+		Name: "test",
+		Type: utils.Image,
+	})
+	return suggestions
+}
+
+func findResults(suggestions []Result, name string) []Result {
+	var results []Result
+	for _, suggestion := range suggestions {
+		if suggestion.Name == name {
+			results = append(results, suggestion)
+		}
+	}
+	return results
+}
+
+func findInfo(info []Info, name string) []Info {
+	var found []Info
+	for _, entry := range info {
+		if entry.Name == name {
+			found = append(found, entry)
+		}
 	}
+	return found
 }
@@ -0,0 +1,94 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// standardFrontends are the Dockerfile frontends whose syntax is the one analyzed by doa
+var standardFrontends = []string{
+	"docker/dockerfile",
+	"docker.io/docker/dockerfile",
+	"docker/dockerfile-upstream",
+	"docker.io/docker/dockerfile-upstream",
+}
+
+// AnalyzeSyntaxDirective reports the frontend selected by the '# syntax=' parser directive, if any, as info and
+// the results if it is not the standard or a stable one
+func AnalyzeSyntaxDirective(content []byte) ([]Result, []Info) {
+	frontend, _, location, ok := parser.DetectSyntax(content)
+	if !ok {
+		return nil, nil
+	}
+	line := Line{}
+	if len(location) > 0 {
+		line = Line{
+			Start: location[0].Start.Line,
+			End:   location[0].End.Line,
+		}
+	}
+	info := []Info{
+		{
+			Name:        "Syntax frontend",
+			Description: fmt.Sprintf("the Containerfile selects the syntax frontend %s at line %d", frontend, line.Start),
+		},
+	}
+	var results []Result
+	name, tag := splitFrontend(frontend)
+	if !isStandardFrontend(name) {
+		results = append(results, Result{
+			Name:     "Non-standard syntax frontend",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf(`the syntax frontend %s at line %d is not the standard Dockerfile one, the file could use a different syntax and this analysis may not reflect what is built. 
+		OpenShift Docker strategy builds ignore the syntax directive and build the file as a plain Containerfile`, frontend, line.Start),
+		})
+	} else if strings.Contains(tag, "labs") {
+		results = append(results, Result{
+			Name:     "Experimental syntax frontend",
+			Status:   StatusFailed,
+			Severity: SeverityLow,
+			Description: fmt.Sprintf(`the syntax frontend %s at line %d enables experimental features. 
+		OpenShift Docker strategy builds ignore the syntax directive and could not support them`, frontend, line.Start),
+		})
+	}
+	return results, info
+}
+
+// splitFrontend splits the frontend image reference in name and tag, ignoring the digest
+func splitFrontend(frontend string) (string, string) {
+	name := strings.SplitN(frontend, "@", 2)[0]
+	tag := ""
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		name, tag = name[:index], name[index+1:]
+	}
+	return name, tag
+}
+
+func isStandardFrontend(name string) bool {
+	for _, standard := range standardFrontends {
+		if strings.EqualFold(name, standard) {
+			return true
+		}
+	}
+	return false
+}
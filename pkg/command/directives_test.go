@@ -0,0 +1,65 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+)
+
+func TestEscapeDirective(t *testing.T) {
+	results, _ := AnalyzePath(context.Background(), "resources/Containerfile.escape", decompiler.Options{})
+	suggestions := findResults(results, "Permission set")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "at line 3-4") {
+		t.Errorf("Expected wrong group permissions error but it was %v", suggestions)
+	}
+}
+
+func TestStandardSyntaxFrontend(t *testing.T) {
+	results, info := AnalyzeSyntaxDirective([]byte("# syntax=docker/dockerfile:1.4\nFROM scratch\n"))
+	if len(results) != 0 {
+		t.Errorf("Expected no finding but they were %v", results)
+	}
+	if len(info) != 1 || !strings.Contains(info[0].Description, "docker/dockerfile:1.4 at line 1") {
+		t.Errorf("Expected the syntax frontend to be reported as info but it was %v", info)
+	}
+}
+
+func TestExperimentalSyntaxFrontend(t *testing.T) {
+	results, _ := AnalyzeSyntaxDirective([]byte("# syntax=docker/dockerfile:1-labs\nFROM scratch\n"))
+	if len(findResults(results, "Experimental syntax frontend")) != 1 {
+		t.Errorf("Expected experimental syntax frontend error but it was %v", results)
+	}
+}
+
+func TestNonStandardSyntaxFrontend(t *testing.T) {
+	results, _ := AnalyzePath(context.Background(), "resources/Containerfile.customsyntax", decompiler.Options{})
+	suggestions := findResults(results, "Non-standard syntax frontend")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "docker.io/example/custom-frontend:1.0") {
+		t.Errorf("Expected non-standard syntax frontend error but it was %v", suggestions)
+	}
+}
+
+func TestNoSyntaxDirective(t *testing.T) {
+	if results, info := AnalyzeSyntaxDirective([]byte("FROM scratch\n")); len(results) != 0 || len(info) != 0 {
+		t.Errorf("Expected no results nor info but they were %v %v", results, info)
+	}
+}
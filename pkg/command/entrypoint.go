@@ -0,0 +1,37 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// Entrypoint handles both the ENTRYPOINT and CMD instructions
+type Entrypoint struct{}
+
+func (e Entrypoint) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
+	ctx = recordEntrypointFacts(ctx, node.Value)
+	return recordPasswdFacts(ctx, node.Value, source, line)
+}
+
+func (e Entrypoint) PostProcess(ctx context.Context) []Result {
+	return analyzePasswdFacts(ctx)
+}
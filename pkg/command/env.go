@@ -0,0 +1,44 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+type Env struct{}
+
+func (e Env) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
+	ctx = recordNssWrapperFacts(ctx, node.Value)
+	// the arguments are the names and values of the variables, the values are recorded with their name
+	if instruction := GetInstruction(ctx); instruction != nil {
+		for name := instruction.Next; name != nil && name.Next != nil; name = name.Next.Next {
+			if name == node {
+				return recordNpmPrefix(ctx, name.Value, name.Next.Value)
+			}
+		}
+	}
+	return ctx
+}
+
+func (e Env) PostProcess(ctx context.Context) []Result {
+	return nil
+}
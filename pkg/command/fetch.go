@@ -0,0 +1,62 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+const interpreters = `(?:sudo(?:\s+-\S+)*\s+)?(?:\S*/)?(?:env\s+)?(sh|bash|zsh|ash|dash|ksh|python[0-9.]*|perl|ruby|node)\b`
+
+var pipeToShellExpr = regexp.MustCompile(`\b(curl|wget)\s[^|]*\|\s*` + interpreters)
+var substitutionToShellExpr = regexp.MustCompile(interpreters + `\s+(?:-c\s+)?["']?(?:<\(|\$\()\s*(curl|wget)\s`)
+var fetchURLExpr = regexp.MustCompile(`https?://[^\s|"')]+`)
+
+/*
+	to be tested on
+
+curl -fsSL https://get.example.com | sh
+wget -O- https://example.com/install.sh | bash
+curl -sL https://deb.nodesource.com/setup_18.x | sudo -E bash -
+bash <(curl -s https://example.com/install.sh)
+sh -c "$(curl -fsSL https://example.com/install.sh)"
+*/
+func (r Run) analyzeRemoteFetchCommand(s string, source utils.Source, line Line) *Result {
+	var interpreter string
+	if match := pipeToShellExpr.FindStringSubmatch(s); match != nil {
+		interpreter = match[2]
+	} else if match := substitutionToShellExpr.FindStringSubmatch(s); match != nil {
+		interpreter = match[1]
+	} else {
+		return nil
+	}
+	url := fetchURLExpr.FindString(s)
+	if url == "" {
+		url = "remote content"
+	}
+	return &Result{
+		Name:     "Remote script piped to interpreter",
+		Status:   StatusFailed,
+		Severity: SeverityHigh,
+		Description: fmt.Sprintf(`%s is executed by %s %s without being verified and could be rejected by OpenShift image policies. 
+		Download it to a file, verify it with sha256sum -c or a signature and then execute it`, url, interpreter, GenerateErrorLocation(source, line)),
+	}
+}
@@ -0,0 +1,76 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemoteScriptPipedToInterpreter(t *testing.T) {
+	for _, cmd := range []string{
+		"curl -fsSL https://get.example.com | sh",
+		"wget -O- https://example.com/install.sh | bash",
+		"curl -sL https://bootstrap.example.com/get-pip.py | /usr/bin/env python3 -",
+		"bash <(curl -s https://example.com/install.sh)",
+		`sh -c "$(curl -fsSL https://example.com/install.sh)"`,
+	} {
+		t.Run(cmd, func(t *testing.T) {
+			suggestions := verifyParsingCommand(t, cmd, 1)
+			if len(suggestions) == 1 && !strings.Contains(suggestions[0].Description, "https://") {
+				t.Errorf("Expected the URL to be reported but it was %s", suggestions[0].Description)
+			}
+		})
+	}
+}
+
+func TestRemoteScriptDownloadedAndVerified(t *testing.T) {
+	verifyParsingCommand(t, "curl -fsSLo install.sh https://get.example.com", 0)
+	verifyParsingCommand(t, "curl -fsSL https://example.com/sums | shasum -c", 0)
+}
+
+func TestRemoteAddWithoutChecksum(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+ADD https://example.com/app.jar /deployments/
+USER 1001
+`), "Remote content added without checksum")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "https://example.com/app.jar") {
+		t.Errorf("Expected remote ADD without checksum error but it was %v", suggestions)
+	}
+}
+
+func TestRemoteAddWithChecksum(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d https://example.com/app.jar /deployments/
+ADD app.tar.gz /app/
+USER 1001
+`)
+	if len(findResults(suggestions, "Remote content added without checksum")) != 0 || len(findResults(suggestions, "Remote archive not extracted")) != 0 {
+		t.Errorf("Expected no remote ADD errors but they were %v", suggestions)
+	}
+}
+
+func TestRemoteAddOfArchive(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+ADD https://example.com/app.tar.gz /app/
+USER 1001
+`), "Remote archive not extracted")
+	if len(suggestions) != 1 {
+		t.Errorf("Expected remote archive error but it was %v", suggestions)
+	}
+}
@@ -0,0 +1,154 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// analysisState is the state of an analysis carried by its context, which the instructions of a parent image
+// depend on and update. The state after analyzing a parent image is cached as its findings
+type analysisState struct {
+	// Results are the results of each handler, by instruction
+	Results       map[string][]Result
+	User          string
+	UserProcessed bool
+	Npm           npmFacts
+	Passwd        passwdState
+	StageUsers    map[string]string
+	Lineage       []Ancestor
+}
+
+// passwdState is the stored form of passwdFacts
+type passwdState struct {
+	WritableLocation   string
+	EntrypointCommands []string
+	Scripts            []string
+	NssWrapper         bool
+	UsernameCommand    string
+	UsernameLocation   string
+}
+
+// resultKeys are the context keys of the results of the handlers
+var resultKeys = map[string]interface{}{
+	"add":    addResultKey,
+	"expose": exposeResultKey,
+	"from":   fromResultKey,
+	"run":    runResultKey,
+	"user":   userResultKey,
+}
+
+func getAnalysisState(ctx context.Context) analysisState {
+	facts := getPasswdFacts(ctx)
+	state := analysisState{
+		Results:       map[string][]Result{},
+		User:          GetCurrentUser(ctx),
+		UserProcessed: ctx.Value(userProcessedKey) != nil,
+		Npm:           getNpmFacts(ctx),
+		Passwd: passwdState{
+			WritableLocation:   facts.writableLocation,
+			EntrypointCommands: facts.entrypointCommands,
+			Scripts:            facts.scripts,
+			NssWrapper:         facts.nssWrapper,
+			UsernameCommand:    facts.usernameCommand,
+			UsernameLocation:   facts.usernameLocation,
+		},
+		StageUsers: getStageUsers(ctx),
+		Lineage:    GetLineage(ctx),
+	}
+	for name, key := range resultKeys {
+		if results, ok := ctx.Value(key).([]Result); ok {
+			state.Results[name] = results
+		}
+	}
+	return state
+}
+
+// apply sets the state in the context
+func (state analysisState) apply(ctx context.Context) context.Context {
+	for name, key := range resultKeys {
+		if results, ok := state.Results[name]; ok {
+			ctx = context.WithValue(ctx, key, results)
+		}
+	}
+	ctx = context.WithValue(ctx, userCurrentKey, state.User)
+	if state.UserProcessed {
+		ctx = context.WithValue(ctx, userProcessedKey, true)
+	}
+	ctx = context.WithValue(ctx, npmFactsKey, state.Npm)
+	ctx = context.WithValue(ctx, passwdFactsKey, passwdFacts{
+		writableLocation:   state.Passwd.WritableLocation,
+		entrypointCommands: state.Passwd.EntrypointCommands,
+		scripts:            state.Passwd.Scripts,
+		nssWrapper:         state.Passwd.NssWrapper,
+		usernameCommand:    state.Passwd.UsernameCommand,
+		usernameLocation:   state.Passwd.UsernameLocation,
+	})
+	if state.StageUsers != nil {
+		ctx = context.WithValue(ctx, stageUsersKey, state.StageUsers)
+	}
+	return context.WithValue(ctx, lineageKey, state.Lineage)
+}
+
+// findingsKey identifies the findings of a parent image: its digest, the name it is referred to with, the platform
+// of its own base images, its base image in the catalogue and the state of the analysis before it
+func findingsKey(ctx context.Context, name string, image *decompiler.Image, options decompiler.Options) (string, error) {
+	state, err := json.Marshal(getAnalysisState(ctx))
+	if err != nil {
+		return "", err
+	}
+	base := ""
+	if entry, _ := options.Catalogue().Match(image); entry != nil {
+		base = entry.Digest
+	}
+	return fmt.Sprintf("%s %s %s %s %x", image.Digest, name, options.Platform, base, sha256.Sum256(state)), nil
+}
+
+// analyzeParentImage analyzes the instructions of a parent image, its findings are cached as they only depend on
+// the image and on the state of the analysis before it (e.g. the results of a previous stage)
+func analyzeParentImage(ctx context.Context, name string, image *decompiler.Image, options decompiler.Options) context.Context {
+	source := utils.Source{
+		Name: name,
+		Type: utils.Parent,
+	}
+	findings := options.Cache()
+	if findings == nil || image.Digest == "" {
+		_, ctx = analyzeDecompiledNode(ctx, name, image, source)
+		return ctx
+	}
+	key, err := findingsKey(ctx, name, image, options)
+	var state analysisState
+	if err == nil && findings.Findings(key, &state) {
+		return state.apply(ctx)
+	}
+	fromResults := len(getAnalysisState(ctx).Results["from"])
+	info := len(GetInfo(ctx))
+	_, ctx = analyzeDecompiledNode(ctx, name, image, source)
+	// the findings are not cached when a base image of the parent image was not analyzed, it may be the next time
+	if err == nil && ctx.Err() == nil && len(getAnalysisState(ctx).Results["from"]) == fromResults && len(GetInfo(ctx)) == info {
+		// the cache is a best effort, the analysis does not fail if it can't be written
+		_ = findings.StoreFindings(key, getAnalysisState(ctx))
+	}
+	return ctx
+}
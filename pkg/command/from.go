@@ -20,6 +20,9 @@
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
 	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
@@ -34,27 +37,116 @@ var fromResultKey fromResultKeyType
 
 const SCRATCH_IMAGE_NAME = "scratch"
 
+type stageUsersKeyType struct{}
+type stageCurrentKeyType struct{}
+
+var stageUsersKey stageUsersKeyType
+var stageCurrentKey stageCurrentKeyType
+
 func (f From) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
+	// FROM image [AS name], only the image is analyzed
+	instruction := GetInstruction(ctx)
+	if instruction != nil && instruction.Next != node {
+		return ctx
+	}
+	ctx = endStage(ctx)
+	// the parent images are analyzed outside of any stage
+	ctx = context.WithValue(ctx, stageCurrentKey, "")
+	ctx = f.analyzeBaseImage(ctx, node, instruction, source)
+	return context.WithValue(ctx, stageCurrentKey, stageName(instruction))
+}
+
+func (f From) analyzeBaseImage(ctx context.Context, node *parser.Node, instruction *parser.Node, source utils.Source) context.Context {
+	if user, ok := getStageUsers(ctx)[strings.ToLower(node.Value)]; ok {
+		// the base image is a previous stage, already analyzed
+		return context.WithValue(ctx, userCurrentKey, user)
+	}
+	// a new stage starts as root unless the base image sets a different user
+	ctx = context.WithValue(ctx, userCurrentKey, "")
 	if node.Value == SCRATCH_IMAGE_NAME {
 		return ctx
 	}
-	decompiledNode, err := decompiler.Decompile(node.Value)
-	if err != nil {
+	options := GetDecompilerOptions(ctx)
+	if instruction != nil {
+		if platform := fromPlatform(instruction.Flags, options.Platform); platform != "" {
+			options.Platform = platform
+		}
+	}
+	image, err := decompiler.Decompile(ctx, node.Value, options)
+	if err != nil && ctx.Err() != nil {
+		// the analysis was interrupted, the rest of the Containerfile is still analyzed
+		return appendResults(ctx, fromResultKey, []Result{
+			{
+				Name:        "Analyze error",
+				Status:      StatusFailed,
+				Severity:    SeverityLow,
+				Description: fmt.Sprintf("unable to analyze the base image %s - error %s", node.Value, ctx.Err()),
+				Diagnostics: GetDiagnostics(err),
+			},
+		})
+	} else if err != nil && options.Offline {
+		// the base image may be available once online, it is not an error of the Containerfile
+		return appendInfo(ctx, Info{
+			Name:        "Base image not analyzed",
+			Description: fmt.Sprintf("base image %s not analyzed (offline)", node.Value),
+			Diagnostics: GetDiagnostics(err),
+		})
+	} else if err != nil {
 		// unable to decompile base image
-		return context.WithValue(ctx, fromResultKey, []Result{
+		return appendResults(ctx, fromResultKey, []Result{
 			Result{
 				Name:        "Analyze error",
 				Status:      StatusFailed,
 				Severity:    SeverityLow,
 				Description: fmt.Sprintf("unable to analyze the base image %s", node.Value),
+				Diagnostics: GetDiagnostics(err),
 			},
 		})
 	}
-	_, ctx = AnalyzeNodeFromSource(ctx, decompiledNode, utils.Source{
-		Name: node.Value,
-		Type: utils.Parent,
+	child := ""
+	if source.Type == utils.Parent {
+		child = source.Name
+	}
+	ctx = recordAncestor(ctx, Ancestor{
+		Name:         node.Value,
+		Child:        child,
+		Digest:       image.Digest,
+		Provider:     image.Provider,
+		Instructions: len(image.Node.Children),
 	})
-	return ctx
+	return analyzeParentImage(ctx, node.Value, image, options)
+}
+
+// stageName returns the lowercase name set with FROM image AS name, if any
+func stageName(instruction *parser.Node) string {
+	if instruction == nil || instruction.Next == nil {
+		return ""
+	}
+	as := instruction.Next.Next
+	if as == nil || !strings.EqualFold(as.Value, "as") || as.Next == nil {
+		return ""
+	}
+	return strings.ToLower(as.Next.Value)
+}
+
+// endStage records the user the current stage ends with, so that the stages based on it start with it
+func endStage(ctx context.Context) context.Context {
+	name, _ := ctx.Value(stageCurrentKey).(string)
+	if name == "" {
+		return ctx
+	}
+	users := map[string]string{}
+	for stage, user := range getStageUsers(ctx) {
+		users[stage] = user
+	}
+	users[name] = GetCurrentUser(ctx)
+	return context.WithValue(ctx, stageUsersKey, users)
+}
+
+// getStageUsers returns the user each previous named stage ends with
+func getStageUsers(ctx context.Context) map[string]string {
+	users, _ := ctx.Value(stageUsersKey).(map[string]string)
+	return users
 }
 
 func (f From) PostProcess(ctx context.Context) []Result {
@@ -64,3 +156,42 @@ func (f From) PostProcess(ctx context.Context) []Result {
 	}
 	return result.([]Result)
 }
+
+// fromPlatform returns the platform set with FROM --platform, the build arguments of the build and target platforms
+// are expanded with the build host platform and the target one. An empty string is returned if it is not set or
+// it uses other arguments
+func fromPlatform(flags []string, target string) string {
+	if target == "" {
+		target = decompiler.DefaultPlatform()
+	}
+	build := strings.Split(decompiler.DefaultPlatform(), "/")
+	targetParts := strings.Split(target, "/")
+	args := map[string]string{
+		"BUILDPLATFORM":  decompiler.DefaultPlatform(),
+		"BUILDOS":        build[0],
+		"BUILDARCH":      build[1],
+		"TARGETPLATFORM": target,
+		"TARGETOS":       targetParts[0],
+	}
+	if len(targetParts) > 1 {
+		args["TARGETARCH"] = targetParts[1]
+	}
+	if len(targetParts) > 2 {
+		args["TARGETVARIANT"] = targetParts[2]
+	}
+	for _, flag := range flags {
+		if !strings.HasPrefix(flag, "--platform=") {
+			continue
+		}
+		resolved := true
+		platform := os.Expand(strings.TrimPrefix(flag, "--platform="), func(name string) string {
+			value, ok := args[name]
+			resolved = resolved && ok
+			return value
+		})
+		if resolved {
+			return platform
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+var heredocShellExpr = regexp.MustCompile(`^(?:(?:\S*/)?(?:sh|bash|ash|dash|zsh|ksh)(?:\s+-\S+)*\s+)?<<`)
+var heredocDestinationExpr = regexp.MustCompile(`(?:>>?|\btee(?:\s+-\S+)*\s)\s*["']?([^\s"'<>;&|]+)`)
+
+// isHeredocScript returns true when the heredoc is executed as a shell script
+// e.g. RUN <<EOF or RUN bash -e <<EOF, and not written to a file or piped to another program
+func (r Run) isHeredocScript(command string, heredoc parser.Heredoc) bool {
+	return heredocShellExpr.MatchString(strings.TrimSpace(command)) && heredoc.FileDescriptor == 0
+}
+
+// heredocDestination returns the file a heredoc is written to
+// e.g. RUN cat <<EOF > /entrypoint.sh or RUN tee /entrypoint.sh <<EOF, empty when it is executed or piped
+func (r Run) heredocDestination(command string, heredoc parser.Heredoc) string {
+	if r.isHeredocScript(command, heredoc) {
+		return ""
+	}
+	match := heredocDestinationExpr.FindStringSubmatch(command)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// splitScript returns the commands of a shell script joining the continuation lines and skipping comments
+func splitScript(script string) []string {
+	var commands []string
+	current := ""
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		current += line
+		if current != "" && !strings.HasPrefix(current, "#") {
+			commands = append(commands, current)
+		}
+		current = ""
+	}
+	if current != "" {
+		commands = append(commands, current)
+	}
+	return commands
+}
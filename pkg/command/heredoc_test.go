@@ -0,0 +1,135 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+func TestHeredocScriptIsAnalyzed(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN <<EOF
+mkdir /app
+chmod 700 /app
+EOF
+USER 1001
+`), "Permission set")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "chmod 700 /app") {
+		t.Errorf("Expected wrong group permissions error but it was %v", suggestions)
+	}
+}
+
+func TestHeredocScriptWithContinuationLines(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+USER 1001
+RUN bash -e <<EOF
+dnf install -y \
+  git
+EOF
+`), "Package manager used as non-root user")
+	if len(suggestions) != 1 {
+		t.Errorf("Expected package manager error but it was %v", suggestions)
+	}
+}
+
+func TestHeredocWrittenToFileIsNotAnalyzedAsScript(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch
+RUN cat <<EOF > /usr/bin/uid_entrypoint
+chmod 700 /app
+echo "app:x:$(id -u):0::/app:/sbin/nologin" >> /etc/passwd
+EOF
+RUN chmod g=u /etc/passwd
+USER 1001
+ENTRYPOINT ["/usr/bin/uid_entrypoint"]
+`)
+	if len(findResults(suggestions, "Permission set")) != 0 || len(findResults(suggestions, "Incomplete arbitrary user ID support")) != 0 {
+		t.Errorf("Expected no errors but they were %v", suggestions)
+	}
+}
+
+func TestCacheMountWithNonRootGroup(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN --mount=type=cache,target=/home/app/.cache,uid=1001,gid=1001 pip install flask
+USER 1001
+`), "Owner set")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "owned by group 1001") {
+		t.Errorf("Expected wrong group ID error but it was %v", suggestions)
+	}
+}
+
+func TestCacheMountNotWritableByBuildUser(t *testing.T) {
+	verifyMountResults(t, "--mount=type=cache,target=/opt/app-root/.m2", "Cache mount not writable", 1)
+	verifyMountResults(t, "--mount=type=cache,target=/opt/app-root/.m2,uid=1001", "Cache mount not writable", 0)
+	verifyMountResults(t, "--mount=type=cache,target=/opt/app-root/.m2,mode=0775", "Cache mount not writable", 0)
+}
+
+func TestSecretMountNotReadableByBuildUser(t *testing.T) {
+	verifyMountResults(t, "--mount=type=secret,id=token", "Secret not readable", 1)
+	verifyMountResults(t, "--mount=type=secret,id=token,uid=1001", "Secret not readable", 0)
+	verifyMountResults(t, "--mount=type=secret,id=token,mode=0444", "Secret not readable", 0)
+}
+
+func verifyMountResults(t *testing.T, flag string, name string, numberExpectedErrors int) {
+	suggestions := findResults(analyzeContainerfile(t, "FROM scratch\nUSER 1001\nRUN "+flag+" ls\n"), name)
+	if len(suggestions) != numberExpectedErrors {
+		t.Errorf("Expected %d %s errors for %s but they were %v", numberExpectedErrors, name, flag, suggestions)
+	}
+}
+
+func TestHeredocPipedToProgramIsIgnored(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN python3 <<EOF
+ssh = "remote"
+print(ssh)
+EOF
+USER 1001
+`), "Missing user name for arbitrary user ID")
+	if len(suggestions) != 0 {
+		t.Errorf("Expected no missing user name error but it was %v", suggestions)
+	}
+}
+
+func TestHeredocScriptMakesPasswdWritable(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN <<EOF
+chmod g=u /etc/passwd
+EOF
+USER 1001
+`), "Incomplete arbitrary user ID support")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "made writable by the root group at line 2") {
+		t.Errorf("Expected missing entrypoint error but it was %v", suggestions)
+	}
+}
+
+func TestHeredocDestination(t *testing.T) {
+	heredoc := parser.Heredoc{Name: "EOF"}
+	for command, expected := range map[string]string{
+		"cat <<EOF > /entrypoint.sh":             "/entrypoint.sh",
+		"cat >> \"/etc/profile.d/app.sh\" <<EOF": "/etc/profile.d/app.sh",
+		"tee -a /usr/bin/start <<EOF":            "/usr/bin/start",
+		"python3 <<EOF":                          "",
+		"<<EOF":                                  "",
+	} {
+		if destination := (Run{}).heredocDestination(command, heredoc); destination != expected {
+			t.Errorf("Expected %q to write the heredoc to %q but it was %q", command, expected, destination)
+		}
+	}
+}
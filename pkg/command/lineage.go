@@ -0,0 +1,203 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// Ancestor is a base image decompiled and analyzed while analyzing an image or a Containerfile
+type Ancestor struct {
+	Name string `json:"name"`
+	// Child is the parent image based on the ancestor, empty if it is the analyzed image or Containerfile
+	Child        string `json:"child,omitempty"`
+	Digest       string `json:"digest"`
+	Provider     string `json:"provider"`
+	Instructions int    `json:"instructions"`
+	// Findings are the descriptions of the failed results found in the instructions of the ancestor
+	Findings []string `json:"findings,omitempty"`
+}
+
+type lineageKeyType struct{}
+
+var lineageKey lineageKeyType
+
+// CATALOGUE_PROVIDER is the provider of the base images identified in the catalogue of the images analyzed so far
+const CATALOGUE_PROVIDER = "catalogue"
+
+// recordAncestor adds a base image to the lineage, an image is only recorded once
+func recordAncestor(ctx context.Context, ancestor Ancestor) context.Context {
+	lineage := GetLineage(ctx)
+	for _, recorded := range lineage {
+		if recorded.Name == ancestor.Name {
+			return ctx
+		}
+	}
+	return context.WithValue(ctx, lineageKey, append(append([]Ancestor{}, lineage...), ancestor))
+}
+
+// analyzeDecompiledNode analyzes the instructions of a decompiled image and records it in the catalogue.
+// If its base image is identified in the catalogue, the instructions rebuilt from the history entries of the base
+// image are analyzed as coming from it. The base image declared by a FROM instruction of the history, if any, is used instead
+func analyzeDecompiledNode(ctx context.Context, name string, image *decompiler.Image, source utils.Source) ([]Result, context.Context) {
+	images := GetDecompilerOptions(ctx).Catalogue()
+	base, _ := images.Match(image)
+	// the catalogue is a best effort, the analysis does not fail if it can't be written
+	_ = images.Record(name, image)
+	if base == nil {
+		return AnalyzeNodeFromSource(ctx, image.Node, source)
+	}
+	baseNode, node := splitBase(image, base.HistoryLength)
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Value, "from") {
+			return AnalyzeNodeFromSource(ctx, image.Node, source)
+		}
+	}
+	ctx = recordAncestor(ctx, Ancestor{
+		Name:         base.Name,
+		Child:        source.Name,
+		Digest:       base.Digest,
+		Provider:     CATALOGUE_PROVIDER,
+		Instructions: len(baseNode.Children),
+	})
+	_, ctx = AnalyzeNodeFromSource(ctx, baseNode, utils.Source{
+		Name: base.Name,
+		Type: utils.Parent,
+	})
+	return AnalyzeNodeFromSource(ctx, node, source)
+}
+
+// splitBase splits the instructions rebuilt from the first history entries, those of the base image, from the other ones
+func splitBase(image *decompiler.Image, historyLength int) (*parser.Node, *parser.Node) {
+	base := &parser.Node{}
+	node := &parser.Node{}
+	for _, child := range image.Node.Children {
+		if entry, ok := image.History[child]; ok && entry.Index < historyLength {
+			base.Children = append(base.Children, child)
+		} else {
+			node.Children = append(node.Children, child)
+		}
+	}
+	return base, node
+}
+
+// GetLineage returns the base images analyzed so far, in the order they were found
+func GetLineage(ctx context.Context) []Ancestor {
+	lineage := ctx.Value(lineageKey)
+	if lineage == nil {
+		return nil
+	}
+	return lineage.([]Ancestor)
+}
+
+// attributeFindings sets the findings of each ancestor from the source of the failed results
+func attributeFindings(lineage []Ancestor, results []Result) []Ancestor {
+	attributed := make([]Ancestor, len(lineage))
+	for i, ancestor := range lineage {
+		ancestor.Findings = nil
+		for _, result := range results {
+			if result.Status == StatusFailed && result.Source == ancestor.Name {
+				ancestor.Findings = append(ancestor.Findings, strings.Join(strings.Fields(result.Description), " "))
+			}
+		}
+		attributed[i] = ancestor
+	}
+	return attributed
+}
+
+// parentName returns the name of the parent image of a source, empty if it is the analyzed Containerfile or image
+func parentName(source utils.Source) string {
+	if source.Type != utils.Parent {
+		return ""
+	}
+	return source.Name
+}
+
+// setResultsSource sets the parent image of the source on the results of the handlers which have none yet,
+// i.e. those found while analyzing its instructions
+func setResultsSource(ctx context.Context, source utils.Source) context.Context {
+	name := parentName(source)
+	if name == "" {
+		return ctx
+	}
+	for _, key := range resultKeys {
+		results, ok := ctx.Value(key).([]Result)
+		if !ok {
+			continue
+		}
+		var updated []Result
+		for i, result := range results {
+			if result.Source == "" {
+				if updated == nil {
+					updated = append([]Result{}, results...)
+				}
+				updated[i].Source = name
+			}
+		}
+		if updated != nil {
+			ctx = context.WithValue(ctx, key, updated)
+		}
+	}
+	return ctx
+}
+
+// lineageInfo describes the base images analyzed and the number of findings coming from each of them
+func lineageInfo(lineage []Ancestor) []Info {
+	if len(lineage) == 0 {
+		return nil
+	}
+	var descriptions []string
+	for _, ancestor := range lineage {
+		descriptions = append(descriptions, ancestor.String())
+	}
+	return []Info{
+		{
+			Name:        "Base image lineage",
+			Description: strings.Join(descriptions, "\n"),
+		},
+	}
+}
+
+func (a Ancestor) String() string {
+	name := a.Name
+	if a.Child != "" {
+		name += ", base image of " + a.Child
+	}
+	return fmt.Sprintf("%s, found by the %s provider with digest %s: %d instructions, %d findings",
+		name, a.Provider, a.Digest, a.Instructions, len(a.Findings))
+}
+
+// PathLineage analyzes the Containerfile at path and returns the base images analyzed, with their findings,
+// and the results and info of the analysis
+func PathLineage(ctx context.Context, path string, options decompiler.Options) ([]Ancestor, []Result, []Info) {
+	results, info, lineage := analyzePath(ctx, path, options)
+	return lineage, results, info
+}
+
+// ImageLineage analyzes an image and returns the base images analyzed, with their findings,
+// and the results and info of the analysis
+func ImageLineage(ctx context.Context, image string, options decompiler.Options) ([]Ancestor, []Result, []Info) {
+	results, info, lineage := analyzeImage(ctx, image, options)
+	return lineage, results, info
+}
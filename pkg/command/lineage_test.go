@@ -0,0 +1,168 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+)
+
+func writeImageLayout(t *testing.T, configFile *v1.ConfigFile) string {
+	return writeLayout(t, testutil.NewImage(t, configFile))
+}
+
+func writeLayout(t *testing.T, img v1.Image) string {
+	return "oci:" + testutil.WriteImageLayout(t, "1.0", img) + ":1.0"
+}
+
+func TestLineage(t *testing.T) {
+	grandparent := writeImageLayout(t, &v1.ConfigFile{
+		History: []v1.History{{CreatedBy: "/bin/sh -c chmod 700 /data"}},
+	})
+	parent := writeImageLayout(t, &v1.ConfigFile{
+		History: []v1.History{
+			{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /", Comment: "FROM " + grandparent},
+			{CreatedBy: "/bin/sh -c #(nop) EXPOSE 80/tcp", EmptyLayer: true},
+		},
+	})
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	content := "FROM " + parent + " AS builder\nUSER 1001\nFROM builder\nRUN dnf install -y git\n"
+	if err := os.WriteFile(containerfile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lineage, results, _ := PathLineage(context.Background(), containerfile, decompiler.Options{})
+	if errors := findResults(results, "Analyze error"); len(errors) != 0 {
+		t.Errorf("Expected the stage names not to be analyzed as images but it was %v", errors)
+	}
+	if len(lineage) != 2 {
+		t.Fatalf("Expected the parent and grandparent images in the lineage but it was %+v", lineage)
+	}
+	if lineage[0].Name != parent || lineage[0].Child != "" || lineage[0].Provider != "oci-layout" || lineage[0].Instructions != 3 {
+		t.Errorf("Expected the parent image first but it was %+v", lineage[0])
+	}
+	if lineage[1].Name != grandparent || lineage[1].Child != parent {
+		t.Errorf("Expected the grandparent image based on the parent one but it was %+v", lineage[1])
+	}
+	if len(lineage[0].Findings) != 1 || !strings.Contains(lineage[0].Findings[0], "port 80") {
+		t.Errorf("Expected the exposed port finding to come from the parent image but it was %v", lineage[0].Findings)
+	}
+	if len(lineage[1].Findings) != 1 || !strings.Contains(lineage[1].Findings[0], "chmod 700 /data") {
+		t.Errorf("Expected the permission finding to come from the grandparent image but it was %v", lineage[1].Findings)
+	}
+	if packages := findResults(results, "Package manager used as non-root user"); len(packages) != 1 {
+		t.Errorf("Expected the stage based on builder to run as user 1001 but the results were %v", results)
+	}
+
+	results, info := AnalyzePath(context.Background(), containerfile, decompiler.Options{})
+	if len(findResults(results, "Base image lineage")) != 0 {
+		t.Errorf("Expected the lineage not to be reported as a finding but the results were %v", results)
+	}
+	summary := findInfo(info, "Base image lineage")
+	if len(summary) != 1 || !strings.Contains(summary[0].Description, "base image of "+parent) {
+		t.Errorf("Expected the lineage in the analysis info but it was %v", summary)
+	}
+}
+
+func appendLayer(t *testing.T, img v1.Image, createdBy string) v1.Image {
+	layer, err := random.Layer(512, types.OCILayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.Append(img, mutate.Addendum{
+		Layer:   layer,
+		History: v1.History{CreatedBy: createdBy},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestBaseImageFromCatalogue(t *testing.T) {
+	options := decompiler.Options{CacheDir: t.TempDir()}
+	baseImage, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{OS: "linux", Architecture: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseImage = appendLayer(t, baseImage, "/bin/sh -c chmod 700 /data")
+	base := writeLayout(t, baseImage)
+	app := writeLayout(t, appendLayer(t, baseImage, "/bin/sh -c chown 1001:1001 /app"))
+
+	// before the base image is analyzed, its findings are reported as the ones of the image
+	if _, info := AnalyzeImage(context.Background(), app, options); len(findInfo(info, "Base image lineage")) != 0 {
+		t.Errorf("Expected no base image to be identified but the info was %v", info)
+	}
+	AnalyzeImage(context.Background(), base, options)
+
+	lineage, results, _ := ImageLineage(context.Background(), app, options)
+	if len(lineage) != 1 || lineage[0].Name != base || lineage[0].Provider != CATALOGUE_PROVIDER || lineage[0].Instructions != 1 {
+		t.Fatalf("Expected the base image to be identified in the catalogue but the lineage was %+v", lineage)
+	}
+	if len(lineage[0].Findings) != 1 || !strings.Contains(lineage[0].Findings[0], "chmod 700 /data") {
+		t.Errorf("Expected the permission finding to come from the base image but it was %v", lineage[0].Findings)
+	}
+	owners := findResults(results, "Owner set")
+	if len(owners) != 1 || strings.Contains(owners[0].Description, "parent image") {
+		t.Errorf("Expected the owner finding to come from the image but it was %v", owners)
+	}
+}
+
+func TestCatalogueDisabledWithoutCache(t *testing.T) {
+	options := decompiler.Options{CacheDir: t.TempDir(), NoCache: true}
+	baseImage, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{OS: "linux", Architecture: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseImage = appendLayer(t, baseImage, "/bin/sh -c chmod 700 /data")
+	base := writeLayout(t, baseImage)
+	app := writeLayout(t, appendLayer(t, baseImage, "/bin/sh -c chown 1001:1001 /app"))
+
+	AnalyzeImage(context.Background(), base, options)
+	if lineage, _, _ := ImageLineage(context.Background(), app, options); len(lineage) != 0 {
+		t.Errorf("Expected no base image to be identified without cache but the lineage was %+v", lineage)
+	}
+	if _, err := os.Stat(filepath.Join(options.CacheDir, "catalogue.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no catalogue to be written without cache but it was: %v", err)
+	}
+}
+
+func TestFindingsAttributedBySource(t *testing.T) {
+	lineage := attributeFindings([]Ancestor{{Name: "ubi9"}, {Name: "ubi9-minimal"}}, []Result{
+		{Status: StatusFailed, Description: "port 80 exposed in parent image ubi9-minimal could be wrong", Source: "ubi9-minimal"},
+		{Status: StatusFailed, Description: "port 80 exposed in parent image ubi9", Source: "ubi9"},
+		{Status: StatusFailed, Description: "chmod 700 /data at line 3 mentions parent image ubi9"},
+	})
+	if len(lineage[0].Findings) != 1 || lineage[0].Findings[0] != "port 80 exposed in parent image ubi9" {
+		t.Errorf("Expected the finding of ubi9 only to be attributed to it but it was %v", lineage[0].Findings)
+	}
+	if len(lineage[1].Findings) != 1 || !strings.Contains(lineage[1].Findings[0], "ubi9-minimal") {
+		t.Errorf("Expected the finding of ubi9-minimal only to be attributed to it but it was %v", lineage[1].Findings)
+	}
+}
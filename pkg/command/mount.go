@@ -0,0 +1,100 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// parseMountFlag returns the options of a --mount flag, e.g. --mount=type=cache,target=/root/.m2,uid=1001
+func parseMountFlag(flag string) map[string]string {
+	if !strings.HasPrefix(flag, "--mount=") {
+		return nil
+	}
+	options := map[string]string{
+		"type": "bind",
+	}
+	for _, option := range strings.Split(strings.TrimPrefix(flag, "--mount="), ",") {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) == 2 {
+			options[strings.ToLower(kv[0])] = kv[1]
+		} else {
+			options[strings.ToLower(kv[0])] = "true"
+		}
+	}
+	return options
+}
+
+/*
+	to be tested on
+
+--mount=type=cache,target=/root/.m2
+--mount=type=cache,target=/home/app/.cache,uid=1001,gid=1001
+--mount=type=secret,id=token
+--mount=type=secret,id=token,uid=1001
+*/
+func (r Run) analyzeMountFlags(ctx context.Context, flags []string, source utils.Source, line Line) []Result {
+	var results []Result
+	user := GetCurrentUser(ctx)
+	for _, flag := range flags {
+		options := parseMountFlag(flag)
+		if options == nil {
+			continue
+		}
+		target := options["target"]
+		if target == "" {
+			target = options["dst"]
+		}
+		switch options["type"] {
+		case "cache":
+			if gid, ok := options["gid"]; ok && gid != "0" {
+				results = append(results, Result{
+					Name:     "Owner set",
+					Status:   StatusFailed,
+					Severity: SeverityMedium,
+					Description: fmt.Sprintf(`cache mount on %s %s is owned by group %s and could cause an unexpected behavior. 
+		In OpenShift the group ID must always be set to the root group (0)`, target, GenerateErrorLocation(source, line), gid),
+				})
+			}
+			if _, ok := options["uid"]; !ok && !IsRootUser(user) && !isGroupWritableMode(options["mode"]) {
+				results = append(results, Result{
+					Name:     "Cache mount not writable",
+					Status:   StatusFailed,
+					Severity: SeverityMedium,
+					Description: fmt.Sprintf(`cache mount on %s %s is owned by root and not writable by user %s. 
+		Set the uid of the mount to the build user or a mode giving write permissions to the root group (e.g. mode=0775)`, target, GenerateErrorLocation(source, line), user),
+				})
+			}
+		case "secret", "ssh":
+			if _, ok := options["uid"]; !ok && !IsRootUser(user) && !isOtherReadableMode(options["mode"]) {
+				results = append(results, Result{
+					Name:     "Secret not readable",
+					Status:   StatusFailed,
+					Severity: SeverityMedium,
+					Description: fmt.Sprintf(`%s mount %s %s is only readable by root and the build user is %s. 
+		Set the uid of the mount to the build user, and make sure the secret is provided to the OpenShift build`, options["type"], options["id"], GenerateErrorLocation(source, line), user),
+				})
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,223 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+type npmFactsKeyType struct{}
+
+var npmFactsKey npmFactsKeyType
+
+// defaultNpmPrefix is the global prefix of npm when NPM_CONFIG_PREFIX is not set, as in the node images
+const defaultNpmPrefix = "/usr/local"
+
+// npmFacts collects the global installs and the directories made writable by the root group, the global installs
+// are only reported once all the instructions are analyzed since their prefix can be made writable afterwards.
+// They are stored as is in the findings of the parent images
+type npmFacts struct {
+	// Prefix is the value of NPM_CONFIG_PREFIX, empty if not set
+	Prefix   string
+	Installs []npmInstall
+	Writable []writableDir
+}
+
+// npmInstall is a global install and the prefix it installs the packages in
+type npmInstall struct {
+	Command  string
+	Location string
+	Prefix   string
+	Source   string
+}
+
+// writableDir is a directory made writable by the root group, with its content if recursive
+type writableDir struct {
+	Path      string
+	Recursive bool
+}
+
+var packageManagerExpr = regexp.MustCompile(`(?:^|[\s;|(])(apt-get|apt|dnf|yum|microdnf|apk)\s+(?:-\S+\s+)*(install|reinstall|upgrade|update|dist-upgrade|add|del|remove|erase|autoremove)\b`)
+var userInstallExpr = regexp.MustCompile(`(?:^|[\s;|(])(pip3?|python3?\s+-m\s+pip)\s+install\s+(?:.*\s)?--user\b`)
+var globalInstallExpr = regexp.MustCompile(`(?:^|[\s;|(])(npm\s+(?:install|i|add)\s+(?:.*\s)?(?:-g|--global)|yarn\s+global\s+add)\b`)
+var prefixOptionExpr = regexp.MustCompile(`--prefix(?:=|\s+)["']?([^\s"']+)`)
+var recursiveOptionExpr = regexp.MustCompile(`(?:^|\s)(?:-[a-zA-Z]*R[a-zA-Z]*|--recursive)(?:\s|$)`)
+
+func getNpmFacts(ctx context.Context) npmFacts {
+	facts := ctx.Value(npmFactsKey)
+	if facts == nil {
+		return npmFacts{}
+	}
+	return facts.(npmFacts)
+}
+
+// recordNpmPrefix records the global prefix set by an ENV variable, the name is matched as npm does, case insensitively
+func recordNpmPrefix(ctx context.Context, name string, value string) context.Context {
+	if !strings.EqualFold(name, "NPM_CONFIG_PREFIX") {
+		return ctx
+	}
+	facts := getNpmFacts(ctx)
+	facts.Prefix = path.Clean(strings.Trim(value, `"'`))
+	return context.WithValue(ctx, npmFactsKey, facts)
+}
+
+/*
+	to be tested on
+
+npm install -g yarn
+npm install --global --prefix /opt/npm yarn
+chgrp -R 0 /usr/local && chmod -R g=u /usr/local
+fix-permissions /opt/app-root
+*/
+// recordNpmFacts scans a shell command for the global installs and the directories made writable by the root group
+func recordNpmFacts(ctx context.Context, s string, source utils.Source, line Line) context.Context {
+	facts := getNpmFacts(ctx)
+	changed := false
+	for _, command := range commandSeparatorExpr.Split(s, -1) {
+		if globalInstallExpr.MatchString(command) {
+			prefix := facts.Prefix
+			if match := prefixOptionExpr.FindStringSubmatch(command); match != nil {
+				prefix = path.Clean(match[1])
+			}
+			if prefix == "" {
+				prefix = defaultNpmPrefix
+			}
+			facts.Installs = append(append([]npmInstall{}, facts.Installs...), npmInstall{
+				Command:  strings.TrimSpace(command),
+				Location: GenerateErrorLocation(source, line),
+				Prefix:   prefix,
+				Source:   parentName(source),
+			})
+			changed = true
+		}
+		if dirs := groupWritableDirs(command); len(dirs) > 0 {
+			facts.Writable = append(append([]writableDir{}, facts.Writable...), dirs...)
+			changed = true
+		}
+	}
+	if !changed {
+		return ctx
+	}
+	return context.WithValue(ctx, npmFactsKey, facts)
+}
+
+// groupWritableDirs returns the directories a command makes writable by the root group: with chmod giving the group
+// write permission, or with the fix-permissions script of the s2i images
+func groupWritableDirs(command string) []writableDir {
+	fields := strings.Fields(command)
+	if len(fields) < 2 {
+		return nil
+	}
+	var dirs []writableDir
+	switch path.Base(fields[0]) {
+	case "fix-permissions":
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				dirs = append(dirs, writableDir{Path: path.Clean(strings.Trim(field, `"'`)), Recursive: true})
+			}
+		}
+	case "chmod":
+		match := chmodModeExpr.FindStringSubmatch(command)
+		if match == nil || !isGroupWritableChmodMode(match[1]) {
+			return nil
+		}
+		recursive := recursiveOptionExpr.MatchString(command)
+		modeSeen := false
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") {
+				continue
+			}
+			if !modeSeen {
+				modeSeen = true
+				continue
+			}
+			dirs = append(dirs, writableDir{Path: path.Clean(strings.Trim(field, `"'`)), Recursive: recursive})
+		}
+	}
+	return dirs
+}
+
+// groupWritable returns true if the directory is made writable by the root group, directly or recursively
+func (facts npmFacts) groupWritable(dir string) bool {
+	for _, writable := range facts.Writable {
+		if writable.Path == dir || (writable.Recursive && (writable.Path == "/" || strings.HasPrefix(dir, writable.Path+"/"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeNpmFacts reports the global installs in a prefix which is not made writable by the root group
+func analyzeNpmFacts(ctx context.Context) []Result {
+	facts := getNpmFacts(ctx)
+	var results []Result
+	for _, install := range facts.Installs {
+		if facts.groupWritable(install.Prefix) {
+			continue
+		}
+		results = append(results, Result{
+			Name:     "Global install not writable by the root group",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf(`'%s' %s installs packages globally in %s which is not writable by the root group. 
+		In OpenShift, containers are run using arbitrarily assigned user ID belonging to the root group, set NPM_CONFIG_PREFIX to a directory made writable by the root group (chgrp -R 0 and chmod -R g=u on it)`, install.Command, install.Location, install.Prefix),
+			Source: install.Source,
+		})
+	}
+	return results
+}
+
+/*
+	to be tested on
+
+apt-get install -y curl
+dnf -y install git
+microdnf update
+apk add --no-cache bash
+pip install --user flask
+npm install -g yarn
+*/
+func (r Run) analyzePackageCommand(ctx context.Context, s string, source utils.Source, line Line) []Result {
+	var results []Result
+	user := GetCurrentUser(ctx)
+	if match := packageManagerExpr.FindStringSubmatch(s); match != nil && !IsRootUser(user) {
+		results = append(results, Result{
+			Name:     "Package manager used as non-root user",
+			Status:   StatusFailed,
+			Severity: SeverityHigh,
+			Description: fmt.Sprintf(`%s %s used %s as user %s will fail. 
+		In OpenShift Docker strategy builds, package managers must run as root. Switch to USER root before installing packages and back to a non-root user afterwards`, match[1], match[2], GenerateErrorLocation(source, line), user),
+		})
+	}
+	if match := userInstallExpr.FindStringSubmatch(s); match != nil {
+		results = append(results, Result{
+			Name:     "User-scoped install",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf(`'%s' %s installs packages in the HOME of the build user. 
+		In OpenShift, containers are run using arbitrarily assigned user ID which could not find or read them. Install them in a prefix readable by the root group instead`, strings.TrimSpace(s), GenerateErrorLocation(source, line)),
+		})
+	}
+	return results
+}
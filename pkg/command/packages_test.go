@@ -0,0 +1,119 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPackageManagerAsRoot(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch
+RUN dnf install -y python3 && apk add --no-cache bash
+USER 1001
+`)
+	if len(findResults(suggestions, "Package manager used as non-root user")) != 0 {
+		t.Errorf("Expected no package manager errors but they were %v", suggestions)
+	}
+}
+
+func TestPackageManagerAsNonRoot(t *testing.T) {
+	for _, cmd := range []string{"apt-get install -y curl", "dnf -y install git", "yum update -y", "microdnf install tar", "apk add --no-cache bash"} {
+		t.Run(cmd, func(t *testing.T) {
+			suggestions := findResults(analyzeContainerfile(t, "FROM scratch\nUSER 1001\nRUN "+cmd+"\n"), "Package manager used as non-root user")
+			if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "as user 1001 will fail") {
+				t.Errorf("Expected package manager error but it was %v", suggestions)
+			}
+		})
+	}
+}
+
+func TestPackageManagerAfterSwitchingBackToRoot(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch
+USER 1001
+USER root
+RUN dnf install -y git
+USER 1001
+`)
+	if len(findResults(suggestions, "Package manager used as non-root user")) != 0 {
+		t.Errorf("Expected no package manager errors but they were %v", suggestions)
+	}
+}
+
+func TestPipUserInstall(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN pip install --user flask
+USER 1001
+`), "User-scoped install")
+	if len(suggestions) != 1 {
+		t.Errorf("Expected user-scoped install error but it was %v", suggestions)
+	}
+}
+
+func TestNpmGlobalInstallAsNonRoot(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+USER 1001
+RUN npm install -g yarn
+`), "Global install not writable by the root group")
+	if len(suggestions) != 1 {
+		t.Errorf("Expected global install error but it was %v", suggestions)
+	}
+}
+
+func TestNpmGlobalInstallAsRoot(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+ENV NOTE=npm_config_prefix
+RUN npm install -g yarn
+USER 1001
+`), "Global install not writable by the root group")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "/usr/local") {
+		t.Errorf("Expected global install error in the default prefix but it was %v", suggestions)
+	}
+}
+
+func TestNpmGlobalInstallWithPrefix(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+ENV NPM_CONFIG_PREFIX=/opt/app-root/npm
+USER 1001
+RUN npm install -g yarn
+`), "Global install not writable by the root group")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "/opt/app-root/npm") {
+		t.Errorf("Expected global install error in the prefix but it was %v", suggestions)
+	}
+}
+
+func TestNpmGlobalInstallWithGroupWritablePrefix(t *testing.T) {
+	for _, containerfile := range []string{`FROM scratch
+ENV npm_config_prefix="/opt/app-root/npm" PATH=/opt/app-root/npm/bin:$PATH
+RUN npm install -g yarn && chgrp -R 0 /opt/app-root/npm && chmod -R g=u /opt/app-root/npm
+USER 1001
+`, `FROM scratch
+RUN npm install --global --prefix=/opt/npm yarn
+RUN chmod 775 /opt/npm
+USER 1001
+`, `FROM scratch
+RUN npm install -g yarn
+RUN fix-permissions /usr
+USER 1001
+`} {
+		suggestions := findResults(analyzeContainerfile(t, containerfile), "Global install not writable by the root group")
+		if len(suggestions) != 0 {
+			t.Errorf("Expected no global install error for %s but it was %v", containerfile, suggestions)
+		}
+	}
+}
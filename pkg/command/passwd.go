@@ -0,0 +1,234 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+type passwdFactsKeyType struct{}
+
+var passwdFactsKey passwdFactsKeyType
+
+// passwdFacts collects what the image does to support running with an arbitrary user ID
+// i.e. making /etc/passwd writable by the root group and adding the current UID at startup
+// or relying on nss_wrapper
+type passwdFacts struct {
+	writableLocation string
+	// entrypointCommands are the CMD and ENTRYPOINT arguments, scripts the files written by heredocs adding the current UID
+	entrypointCommands []string
+	scripts            []string
+	nssWrapper         bool
+	usernameCommand    string
+	usernameLocation   string
+}
+
+var passwdAppendExpr = regexp.MustCompile(`>>\s*["']?/etc/passwd|uid_entrypoint`)
+var nssWrapperExpr = regexp.MustCompile(`(?i)nss_wrapper`)
+var usernameCommandExpr = regexp.MustCompile(`^(ssh|scp|git|java)$`)
+var commandSeparatorExpr = regexp.MustCompile(`&&|\|\||[;|\n]`)
+var commandPrefixExpr = regexp.MustCompile(`^(?:\w+=\S*|sudo|exec|env|nohup|time)$`)
+var chmodModeExpr = regexp.MustCompile(`chmod\s+(?:-\S+\s+)*(\S+)`)
+
+func getPasswdFacts(ctx context.Context) passwdFacts {
+	facts := ctx.Value(passwdFactsKey)
+	if facts == nil {
+		return passwdFacts{}
+	}
+	return facts.(passwdFacts)
+}
+
+// recordPasswdFacts scans a shell command (from RUN, CMD or ENTRYPOINT) for the arbitrary user ID patterns
+func recordPasswdFacts(ctx context.Context, s string, source utils.Source, line Line) context.Context {
+	facts := getPasswdFacts(ctx)
+	for _, command := range strings.Split(s, "&&") {
+		if facts.writableLocation == "" && isPasswdWritableCommand(command) {
+			facts.writableLocation = GenerateErrorLocation(source, line)
+		}
+	}
+	if nssWrapperExpr.MatchString(s) {
+		facts.nssWrapper = true
+	}
+	if facts.usernameCommand == "" {
+		if command := usernameCommand(s); command != "" {
+			facts.usernameCommand = command
+			facts.usernameLocation = GenerateErrorLocation(source, line)
+		}
+	}
+	return context.WithValue(ctx, passwdFactsKey, facts)
+}
+
+// recordEntrypointFacts records a CMD or ENTRYPOINT argument, which may add the current UID to /etc/passwd at startup
+func recordEntrypointFacts(ctx context.Context, s string) context.Context {
+	facts := getPasswdFacts(ctx)
+	facts.entrypointCommands = append(facts.entrypointCommands, s)
+	return context.WithValue(ctx, passwdFactsKey, facts)
+}
+
+// recordPasswdScript records a script written to a file by a heredoc when it adds the current UID to /etc/passwd,
+// it completes the arbitrary user ID support only when executed by CMD or ENTRYPOINT
+func recordPasswdScript(ctx context.Context, destination string, content string) context.Context {
+	if !passwdAppendExpr.MatchString(content) {
+		return ctx
+	}
+	facts := getPasswdFacts(ctx)
+	facts.scripts = append(facts.scripts, destination)
+	return context.WithValue(ctx, passwdFactsKey, facts)
+}
+
+// entrypoint returns true when CMD or ENTRYPOINT add the current UID to /etc/passwd, directly or by running a recorded script
+func (facts passwdFacts) entrypoint() bool {
+	for _, command := range facts.entrypointCommands {
+		if passwdAppendExpr.MatchString(command) {
+			return true
+		}
+		for _, script := range facts.scripts {
+			for _, field := range strings.Fields(command) {
+				if path.Base(strings.Trim(field, `"'`)) == path.Base(script) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// usernameCommand returns the first command requiring a user name run by a shell command,
+// only the command word of each command is checked so that the packages installed are ignored
+func usernameCommand(s string) string {
+	for _, command := range commandSeparatorExpr.Split(s, -1) {
+		if packageManagerExpr.MatchString(command) {
+			continue
+		}
+		for _, field := range strings.Fields(command) {
+			field = strings.Trim(field, `"'()`)
+			if commandPrefixExpr.MatchString(field) {
+				continue
+			}
+			if usernameCommandExpr.MatchString(path.Base(field)) {
+				return path.Base(field)
+			}
+			break
+		}
+	}
+	return ""
+}
+
+func recordNssWrapperFacts(ctx context.Context, s string) context.Context {
+	if !nssWrapperExpr.MatchString(s) {
+		return ctx
+	}
+	facts := getPasswdFacts(ctx)
+	facts.nssWrapper = true
+	return context.WithValue(ctx, passwdFactsKey, facts)
+}
+
+/*
+	to be tested on
+
+chmod g=u /etc/passwd
+chmod g+w /etc/passwd
+chmod -R 664 /etc/passwd
+*/
+func isPasswdWritableCommand(s string) bool {
+	if !IsCommand(s, "chmod") || !strings.Contains(s, "/etc/passwd") {
+		return false
+	}
+	match := chmodModeExpr.FindStringSubmatch(s)
+	if len(match) == 0 {
+		return false
+	}
+	return isGroupWritableChmodMode(match[1])
+}
+
+// isGroupWritableChmodMode returns true if a numeric or symbolic chmod mode gives the group the write permission
+func isGroupWritableChmodMode(mode string) bool {
+	if isNumericMode(mode) {
+		return isGroupWritableMode(mode)
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		if clause == "g=u" || (strings.ContainsAny(clause, "ga") && strings.ContainsAny(clause, "+=") && strings.Contains(clause, "w")) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumericMode(mode string) bool {
+	if mode == "" {
+		return false
+	}
+	for _, c := range mode {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+func isGroupWritableMode(mode string) bool {
+	return isNumericMode(mode) && len(mode) >= 3 && strings.ContainsAny(mode[len(mode)-2:len(mode)-1], "2367")
+}
+
+func isOtherReadableMode(mode string) bool {
+	return isNumericMode(mode) && len(mode) >= 3 && strings.ContainsAny(mode[len(mode)-1:], "4567")
+}
+
+func analyzePasswdFacts(ctx context.Context) []Result {
+	facts := getPasswdFacts(ctx)
+	if facts.nssWrapper {
+		return nil
+	}
+	var results []Result
+	entrypoint := facts.entrypoint()
+	if facts.writableLocation != "" && !entrypoint {
+		results = append(results, Result{
+			Name:     "Incomplete arbitrary user ID support",
+			Status:   StatusFailed,
+			Severity: SeverityLow,
+			Description: fmt.Sprintf(`/etc/passwd is made writable by the root group %s but no entrypoint adding the current user ID to it was found. 
+		In OpenShift, containers are run using arbitrarily assigned user ID, ignore this if the entrypoint script already appends it`, facts.writableLocation),
+		})
+	}
+	if entrypoint && facts.writableLocation == "" {
+		results = append(results, Result{
+			Name:     "Incomplete arbitrary user ID support",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: `the entrypoint adds the current user ID to /etc/passwd but /etc/passwd is not writable by the root group. 
+		Run 'chmod g=u /etc/passwd' so that the arbitrarily assigned user ID used by OpenShift can update it`,
+		})
+	}
+	if facts.usernameCommand != "" && (facts.writableLocation == "" || !entrypoint) {
+		results = append(results, Result{
+			Name:     "Missing user name for arbitrary user ID",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf(`%s used %s could require a user name for the current user ID. 
+		In OpenShift, containers are run using arbitrarily assigned user ID which has no entry in /etc/passwd. 
+		Make /etc/passwd writable by the root group and add the current user ID from the entrypoint or use nss_wrapper`, facts.usernameCommand, facts.usernameLocation),
+		})
+	}
+	return results
+}
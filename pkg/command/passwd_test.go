@@ -0,0 +1,143 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswdWritableCommands(t *testing.T) {
+	for _, cmd := range []string{"chmod g=u /etc/passwd", "chmod g+w /etc/passwd", "chmod -R 664 /etc/passwd", "chmod ug+rw,o-w /etc/passwd"} {
+		if !isPasswdWritableCommand(cmd) {
+			t.Errorf("Expected %s to make /etc/passwd writable by the root group", cmd)
+		}
+	}
+	for _, cmd := range []string{"chmod 644 /etc/passwd", "chmod g+r /etc/passwd", "chmod g=u /app"} {
+		if isPasswdWritableCommand(cmd) {
+			t.Errorf("Expected %s to not make /etc/passwd writable by the root group", cmd)
+		}
+	}
+}
+
+func TestPasswdWritableWithoutEntrypoint(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN chmod g=u /etc/passwd
+USER 1001
+`), "Incomplete arbitrary user ID support")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "no entrypoint adding the current user ID") {
+		t.Errorf("Expected missing entrypoint error but it was %v", suggestions)
+	}
+}
+
+func TestPasswdEntrypointWithoutWritablePasswd(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+USER 1001
+ENTRYPOINT ["/usr/bin/uid_entrypoint"]
+`), "Incomplete arbitrary user ID support")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "is not writable by the root group") {
+		t.Errorf("Expected passwd not writable error but it was %v", suggestions)
+	}
+}
+
+func TestPasswdCompletePattern(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch
+RUN dnf install -y git && chmod g=u /etc/passwd
+USER 1001
+ENTRYPOINT ["/usr/bin/uid_entrypoint"]
+CMD ["git", "pull"]
+`)
+	if len(findResults(suggestions, "Incomplete arbitrary user ID support")) != 0 || len(findResults(suggestions, "Missing user name for arbitrary user ID")) != 0 {
+		t.Errorf("Expected no arbitrary user ID errors but they were %v", suggestions)
+	}
+}
+
+func TestUsernameRequiredWithoutPasswdSupport(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+USER 1001
+CMD ["ssh", "remote"]
+`), "Missing user name for arbitrary user ID")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "ssh used at line 3") {
+		t.Errorf("Expected missing user name error but it was %v", suggestions)
+	}
+}
+
+func TestUsernameRequiredWithNssWrapper(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+ENV LD_PRELOAD=libnss_wrapper.so NSS_WRAPPER_PASSWD=/tmp/passwd
+USER 1001
+CMD ["ssh", "remote"]
+`), "Missing user name for arbitrary user ID")
+	if len(suggestions) != 0 {
+		t.Errorf("Expected no missing user name error but it was %v", suggestions)
+	}
+}
+
+func TestPasswdAppendedAtBuildTime(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN chmod g=u /etc/passwd && echo "app:x:1001:0::/app:/sbin/nologin" >> /etc/passwd
+USER 1001
+`), "Incomplete arbitrary user ID support")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "no entrypoint adding the current user ID") {
+		t.Errorf("Expected missing entrypoint error but it was %v", suggestions)
+	}
+}
+
+func TestPasswdEntrypointScriptWrittenByHeredoc(t *testing.T) {
+	script := `FROM scratch
+RUN chmod g=u /etc/passwd
+RUN cat <<EOF > /usr/local/bin/start.sh
+echo "app:x:$(id -u):0::/app:/sbin/nologin" >> /etc/passwd
+exec "$@"
+EOF
+USER 1001
+`
+	if suggestions := findResults(analyzeContainerfile(t, script+`ENTRYPOINT ["start.sh"]
+`), "Incomplete arbitrary user ID support"); len(suggestions) != 0 {
+		t.Errorf("Expected no arbitrary user ID errors but they were %v", suggestions)
+	}
+	if suggestions := findResults(analyzeContainerfile(t, script), "Incomplete arbitrary user ID support"); len(suggestions) != 1 {
+		t.Errorf("Expected missing entrypoint error when the script is not executed but it was %v", suggestions)
+	}
+}
+
+func TestUsernameCommand(t *testing.T) {
+	for cmd, expected := range map[string]string{
+		"dnf install -y git && dnf clean all":       "",
+		"apt-get install -y openssh-client":         "",
+		"sudo yum install -y java-17-openjdk; ls":   "",
+		"cd /app && git pull":                       "git",
+		"JAVA_OPTS=-Xmx1g /usr/bin/java -jar app":   "java",
+		"exec ssh remote":                           "ssh",
+		"echo \"ssh remote\" > /tmp/log; cat /path": "",
+	} {
+		if command := usernameCommand(cmd); command != expected {
+			t.Errorf("Expected %q to run %q but it was %q", cmd, expected, command)
+		}
+	}
+}
+
+func TestUsernameCommandIgnoresInstalledPackages(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN dnf install -y git && dnf clean all
+USER 1001
+`), "Missing user name for arbitrary user ID")
+	if len(suggestions) != 0 {
+		t.Errorf("Expected no missing user name error but it was %v", suggestions)
+	}
+}
@@ -0,0 +1,142 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+)
+
+// AnalyzeImagePlatforms analyzes the image of every platform of a multi-architecture image. The findings of the
+// other platforms are reported if they differ from the ones of the selected platform, with the differences
+// of user, exposed ports and history between the platforms
+func AnalyzeImagePlatforms(ctx context.Context, image string, options decompiler.Options) ([]Result, []Info) {
+	images, err := decompiler.DecompilePlatforms(ctx, image, options)
+	if err != nil {
+		return []Result{
+			{
+				Name:        "Analyze error",
+				Status:      StatusFailed,
+				Severity:    SeverityCritical,
+				Description: fmt.Sprintf("unable to analyze %s - error %s", image, decompileError(ctx, err)),
+				Diagnostics: GetDiagnostics(err),
+			},
+		}, nil
+	}
+	results, info, lineage := analyzeDecompiledImage(ctx, image, images[0], options)
+	reported := map[string]bool{}
+	for _, result := range results {
+		reported[result.Description] = true
+	}
+	for _, platformImage := range images[1:] {
+		platformResults, platformInfo, _ := analyzeDecompiledImage(ctx, image, platformImage, options)
+		for _, result := range platformResults {
+			if reported[result.Description] {
+				continue
+			}
+			reported[result.Description] = true
+			result.Description = fmt.Sprintf("%s (platform %s)", result.Description, platformImage.Platform)
+			results = append(results, result)
+		}
+		for _, platformEntry := range platformInfo {
+			if platformEntry.Name == "Image source" {
+				info = append(info, platformEntry)
+			}
+		}
+	}
+	return append(results, comparePlatforms(image, images)...), append(info, lineageInfo(lineage)...)
+}
+
+// comparePlatforms reports the user, exposed ports and history which differ between the images of the platforms
+func comparePlatforms(image string, images []*decompiler.Image) []Result {
+	var results []Result
+	aspects := []struct {
+		name     string
+		severity ResultSeverity
+		value    func(*parser.Node) string
+	}{
+		{"user", SeverityMedium, imageUser},
+		{"exposed ports", SeverityMedium, imagePorts},
+		{"history", SeverityLow, imageHistory},
+	}
+	for _, aspect := range aspects {
+		values := []string{}
+		differ := false
+		for _, platformImage := range images {
+			value := aspect.value(platformImage.Node)
+			differ = differ || value != aspect.value(images[0].Node)
+			values = append(values, fmt.Sprintf("%s on %s", value, platformImage.Platform))
+		}
+		if differ {
+			results = append(results, Result{
+				Name:     "Platform differences",
+				Status:   StatusFailed,
+				Severity: aspect.severity,
+				Description: fmt.Sprintf("the %s of image %s differs between platforms: %s. The image could behave differently "+
+					"depending on the architecture of the OpenShift nodes", aspect.name, image, strings.Join(values, ", ")),
+			})
+		}
+	}
+	return results
+}
+
+// imageUser returns the user set by the last USER instruction, root if none
+func imageUser(node *parser.Node) string {
+	user := "root"
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Value, "user") && child.Next != nil {
+			user = child.Next.Value
+		}
+	}
+	return user
+}
+
+// imagePorts returns the sorted ports of the EXPOSE instructions
+func imagePorts(node *parser.Node) string {
+	ports := []string{}
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Value, "expose") {
+			for n := child.Next; n != nil; n = n.Next {
+				ports = append(ports, n.Value)
+			}
+		}
+	}
+	if len(ports) == 0 {
+		return "none"
+	}
+	sort.Strings(ports)
+	return strings.Join(ports, " ")
+}
+
+// imageHistory returns the number of instructions rebuilt from the history and a short digest of them
+func imageHistory(node *parser.Node) string {
+	hash := sha256.New()
+	for _, child := range node.Children {
+		for n := child; n != nil; n = n.Next {
+			hash.Write([]byte(n.Value + " "))
+		}
+		hash.Write([]byte("\n"))
+	}
+	return fmt.Sprintf("%d instructions (%x)", len(node.Children), hash.Sum(nil)[:4])
+}
@@ -0,0 +1,71 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler"
+)
+
+func TestFromPlatform(t *testing.T) {
+	build := decompiler.DefaultPlatform()
+	expected := map[string]string{
+		"--platform=linux/arm64":           "linux/arm64",
+		"--platform=$BUILDPLATFORM":        build,
+		"--platform=${TARGETPLATFORM}":     "linux/s390x",
+		"--platform=$TARGETOS/$TARGETARCH": "linux/s390x",
+		"--platform=$BUILDOS/$TARGETARCH":  "linux/s390x",
+		"--platform=linux/${CUSTOM_ARCH}":  "",
+	}
+	for flag, platform := range expected {
+		if actual := fromPlatform([]string{flag}, "linux/s390x"); actual != platform {
+			t.Errorf("Expected platform %s for %s but it was %s", platform, flag, actual)
+		}
+	}
+	if actual := fromPlatform(nil, "linux/s390x"); actual != "" {
+		t.Errorf("Expected no platform without flag but it was %s", actual)
+	}
+}
+
+func TestAnalyzeImagePlatforms(t *testing.T) {
+	p := testutil.NewLayout(t)
+	testutil.AppendIndex(t, p, "1.0", testutil.NewIndex(t,
+		testutil.NewImage(t, &v1.ConfigFile{Config: v1.Config{User: "1001"}}),
+		testutil.NewImage(t, &v1.ConfigFile{
+			Architecture: "arm64",
+			History:      []v1.History{{CreatedBy: "/bin/sh -c #(nop) EXPOSE 8080/tcp", EmptyLayer: true}},
+		}),
+	))
+
+	results, info := AnalyzeImagePlatforms(context.Background(), "oci:"+string(p)+":1.0", decompiler.Options{Platform: "linux/amd64"})
+	if differences := findResults(results, "Platform differences"); len(differences) != 3 {
+		t.Errorf("Expected differences of user, exposed ports and history but they were %v", differences)
+	}
+	users := findResults(results, "User set to root")
+	if len(users) != 1 || !strings.HasSuffix(users[0].Description, "(platform linux/arm64)") {
+		t.Errorf("Expected the root user of the linux/arm64 image to be reported but it was %v", users)
+	}
+	if sources := findInfo(info, "Image source"); len(sources) != 2 || !strings.HasSuffix(sources[1].Description, "for platform linux/arm64") {
+		t.Errorf("Expected the source of the image of each platform but it was %v", sources)
+	}
+}
@@ -34,9 +34,34 @@ type runResultKeyType struct{}
 var runResultKey runResultKeyType
 
 func (r Run) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
+	results := r.analyzeShellCommand(ctx, node.Value, source, line)
+	ctx = recordPasswdFacts(ctx, node.Value, source, line)
+	ctx = recordNpmFacts(ctx, node.Value, source, line)
 
+	// heredocs and flags belong to the instruction, analyze them only once with its first argument
+	instruction := GetInstruction(ctx)
+	if instruction != nil && instruction.Next == node {
+		results = append(results, r.analyzeMountFlags(ctx, instruction.Flags, source, line)...)
+		for _, heredoc := range instruction.Heredocs {
+			if r.isHeredocScript(node.Value, heredoc) {
+				for _, command := range splitScript(heredoc.Content) {
+					results = append(results, r.analyzeShellCommand(ctx, command, source, line)...)
+					ctx = recordPasswdFacts(ctx, command, source, line)
+					ctx = recordNpmFacts(ctx, command, source, line)
+				}
+			} else if destination := r.heredocDestination(node.Value, heredoc); destination != "" {
+				// heredocs written to files are often entrypoint scripts
+				ctx = recordPasswdFacts(ctx, heredoc.Content, source, line)
+				ctx = recordPasswdScript(ctx, destination, heredoc.Content)
+			}
+		}
+	}
+	return appendResults(ctx, runResultKey, results)
+}
+
+func (r Run) analyzeShellCommand(ctx context.Context, s string, source utils.Source, line Line) []Result {
 	// let's split the run command by &&. E.g chmod 070 /app && chmod 070 /app/routes && chmod 070 /app/bin
-	splittedCommands := strings.Split(node.Value, "&&")
+	splittedCommands := strings.Split(s, "&&")
 	var results []Result
 	for _, command := range splittedCommands {
 		if r.isChmodCommand(command) {
@@ -49,22 +74,35 @@ func (r Run) Analyze(ctx context.Context, node *parser.Node, source utils.Source
 			if result != nil {
 				results = append(results, *result)
 			}
+		} else if r.isChgrpCommand(command) {
+			result := r.analyzeChgrpCommand(command, source, line)
+			if result != nil {
+				results = append(results, *result)
+			}
+		} else if r.isInstallCommand(command) || r.isMkdirCommand(command) {
+			results = append(results, r.analyzeModeAndGroupOptions(command, source, line)...)
+		} else if r.isSetfaclCommand(command) {
+			results = append(results, r.analyzeSetfaclCommand(command, source, line)...)
 		} else if r.isSudoOrSuCommand(command) {
 			result := r.analyzeSudoAndSuCommand(command, source, line)
 			if result != nil {
 				results = append(results, *result)
 			}
 		}
+		results = append(results, r.analyzePackageCommand(ctx, command, source, line)...)
+		if result := r.analyzeRemoteFetchCommand(command, source, line); result != nil {
+			results = append(results, *result)
+		}
 	}
-	return context.WithValue(ctx, runResultKey, results)
+	return results
 }
 
 func (r Run) PostProcess(ctx context.Context) []Result {
-	result := ctx.Value(runResultKey)
-	if result == nil {
-		return nil
+	var results []Result
+	if result := ctx.Value(runResultKey); result != nil {
+		results = result.([]Result)
 	}
-	return result.([]Result)
+	return append(results, analyzeNpmFacts(ctx)...)
 }
 
 func (r Run) isSudoOrSuCommand(s string) bool {
@@ -110,7 +148,11 @@ func (r Run) analyzeChownCommand(s string, source utils.Source, line Line) *Resu
 	if len(match) == 0 {
 		return nil // errors.New("unable to find any group set by the chown command")
 	}
-	group := match[len(match)-1]
+	return r.analyzeGroup(s, match[len(match)-1], source, line)
+}
+
+// analyzeGroup checks the group set by a command is the root group
+func (r Run) analyzeGroup(s string, group string, source utils.Source, line Line) *Result {
 	if strings.ToLower(group) != "root" && group != "0" {
 		return &Result{
 			Name:     "Owner set",
@@ -141,7 +183,15 @@ func (r Run) analyzeChmodCommand(s string, source utils.Source, line Line) *Resu
 			Description: fmt.Sprintf("unable to fetch args of chmod command %s. Is it correct?", GenerateErrorLocation(source, line)),
 		}
 	}
-	permission := match[1]
+	return r.analyzePermission(s, match[1], source, line)
+}
+
+// analyzePermission checks the numeric permission set by a command gives read/write permissions to the root group
+func (r Run) analyzePermission(s string, permission string, source utils.Source, line Line) *Result {
+	// the leading digit of a 4 digit permission sets the special bits (setuid, setgid and sticky)
+	if len(permission) == 4 {
+		permission = permission[1:]
+	}
 	if len(permission) != 3 {
 		return &Result{
 			Name:        "Syntax error",
@@ -168,3 +218,93 @@ func (r Run) analyzeChmodCommand(s string, source utils.Source, line Line) *Resu
 
 	return nil
 }
+
+func (r Run) isChgrpCommand(s string) bool {
+	return IsCommand(s, "chgrp")
+}
+
+/*
+	to be tested on
+
+chgrp -R appgroup /app
+chgrp --recursive 0 /app
+chgrp -h root /app/link
+*/
+func (r Run) analyzeChgrpCommand(s string, source utils.Source, line Line) *Result {
+	re := regexp.MustCompile(`chgrp\s+(?:-\S+\s+)*([^-\s]\S*)`)
+	match := re.FindStringSubmatch(s)
+	if len(match) == 0 {
+		return nil
+	}
+	return r.analyzeGroup(s, match[1], source, line)
+}
+
+func (r Run) isInstallCommand(s string) bool {
+	re := regexp.MustCompile(`(?:^|[;|]|sudo)\s*(?:\S*/)?install\s`)
+	return re.MatchString(s)
+}
+
+func (r Run) isMkdirCommand(s string) bool {
+	return IsCommand(s, "mkdir")
+}
+
+/*
+	to be tested on
+
+install -d -m 700 /data
+install -Dm755 run.sh /usr/local/bin/run.sh
+install --mode=0644 -g app app.conf /etc/app.conf
+mkdir -m 0700 /cache
+mkdir -p --mode=750 /app
+*/
+func (r Run) analyzeModeAndGroupOptions(s string, source utils.Source, line Line) []Result {
+	var results []Result
+	modeExpr := regexp.MustCompile(`(?:\s-[a-zA-Z]*m\s*|\s--mode[=\s]\s*)([0-7]{3,4})\b`)
+	if match := modeExpr.FindStringSubmatch(s); match != nil {
+		if result := r.analyzePermission(s, match[1], source, line); result != nil {
+			results = append(results, *result)
+		}
+	}
+	groupExpr := regexp.MustCompile(`(?:\s-[a-zA-Z]*g\s*|\s--group[=\s]\s*)([^-\s]\S*)`)
+	if match := groupExpr.FindStringSubmatch(s); match != nil && r.isInstallCommand(s) {
+		if result := r.analyzeGroup(s, match[1], source, line); result != nil {
+			results = append(results, *result)
+		}
+	}
+	return results
+}
+
+func (r Run) isSetfaclCommand(s string) bool {
+	return IsCommand(s, "setfacl")
+}
+
+/*
+	to be tested on
+
+setfacl -m u:1001:rwx /app
+setfacl -R -m g:appgroup:rwX /app
+setfacl -m g:0:rwx,d:g:0:rwx /app
+*/
+func (r Run) analyzeSetfaclCommand(s string, source utils.Source, line Line) []Result {
+	var results []Result
+	entryExpr := regexp.MustCompile(`(?:^|[\s,])(?:d:|default:)?(u|user|g|group):([^:\s,]+):([rwxX-]*)`)
+	for _, match := range entryExpr.FindAllStringSubmatch(s, -1) {
+		kind, id := match[1], match[2]
+		if match[3] == "" || (strings.HasPrefix(kind, "g") && (strings.ToLower(id) == "root" || id == "0")) {
+			continue
+		}
+		entity := "user"
+		if strings.HasPrefix(kind, "g") {
+			entity = "group"
+		}
+		results = append(results, Result{
+			Name:     "Permission set",
+			Status:   StatusFailed,
+			Severity: SeverityMedium,
+			Description: fmt.Sprintf("permission set on %s %s for the %s %s could cause an unexpected behavior. Try setting it for the root group (g:0:%s)\n"+
+				"Explanation - in Openshift, containers are run using arbitrarily assigned user ID and directories and files "+
+				"need to be read/writable by the root group", s, GenerateErrorLocation(source, line), entity, id, match[3]),
+		})
+	}
+	return results
+}
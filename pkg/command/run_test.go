@@ -107,6 +107,61 @@ func TestFailChmodCommandWithInvalidPermissionCode(t *testing.T) {
 	}
 }
 
+func TestCorrectChmodCommandWithSpecialBits(t *testing.T) {
+	verifyParsingCommand(t, "chmod 2775 /app", 0)
+}
+
+func TestCorrectChgrpCommandWithRootGroup(t *testing.T) {
+	verifyParsingCommand(t, "chgrp -R 0 /app", 0)
+	verifyParsingCommand(t, "chgrp --recursive root /app", 0)
+}
+
+func TestFailIfChgrpCommandWithNonRootGroup(t *testing.T) {
+	suggestions := verifyParsingCommand(t, "chgrp -R appgroup /app", 1)
+	if !strings.Contains(suggestions[0].Description, "In OpenShift the group ID must always be set to the root group (0)") {
+		t.Errorf("Expected to be wrong group ID error but it was %s", suggestions[0].Description)
+	}
+}
+
+func TestCorrectInstallAndMkdirCommandsWithGroupPermission(t *testing.T) {
+	verifyParsingCommand(t, "install -d -m 770 /data", 0)
+	verifyParsingCommand(t, "install --mode=0775 -g 0 run.sh /usr/local/bin/run.sh", 0)
+	verifyParsingCommand(t, "mkdir -p -m 0770 /cache", 0)
+	verifyParsingCommand(t, "mkdir -p /cache", 0)
+	verifyParsingCommand(t, "dnf install -y nginx", 0)
+}
+
+func TestFailInstallAndMkdirCommandsWithNonGroupPermission(t *testing.T) {
+	for _, cmd := range []string{"install -d -m 700 /data", "install -Dm755 run.sh /usr/local/bin/run.sh", "mkdir -m 0700 /cache", "mkdir -p --mode=750 /app"} {
+		t.Run(cmd, func(t *testing.T) {
+			suggestions := verifyParsingCommand(t, cmd, 1)
+			if len(suggestions) == 1 && !strings.Contains(suggestions[0].Description, "permission set on") {
+				t.Errorf("Expected to be wrong group permissions error but it was %s", suggestions[0].Description)
+			}
+		})
+	}
+}
+
+func TestFailIfInstallCommandWithNonRootGroup(t *testing.T) {
+	suggestions := verifyParsingCommand(t, "install -m 775 -g app app.conf /etc/app.conf", 1)
+	if !strings.Contains(suggestions[0].Description, "In OpenShift the group ID must always be set to the root group (0)") {
+		t.Errorf("Expected to be wrong group ID error but it was %s", suggestions[0].Description)
+	}
+}
+
+func TestCorrectSetfaclCommandForRootGroup(t *testing.T) {
+	verifyParsingCommand(t, "setfacl -m g:0:rwx,d:g:0:rwx /app", 0)
+	verifyParsingCommand(t, "setfacl -x u:1001 /app", 0)
+}
+
+func TestFailSetfaclCommandForSpecificUser(t *testing.T) {
+	suggestions := verifyParsingCommand(t, "setfacl -m u:1001:rwx /app", 1)
+	if !strings.Contains(suggestions[0].Description, "for the user 1001") {
+		t.Errorf("Expected to be wrong ACL error but it was %s", suggestions[0].Description)
+	}
+	verifyParsingCommand(t, "setfacl -R -m g:appgroup:rwX /app", 1)
+}
+
 func verifyParsingCommand(t *testing.T, cmd string, numberExpectedErrors int) []Result {
 	run := Run{}
 	ctx := context.Background()
@@ -127,3 +182,13 @@ func verifyParsingCommand(t *testing.T, cmd string, numberExpectedErrors int) []
 	}
 	return suggestions
 }
+
+func TestResultsOfEveryRunInstruction(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch
+RUN chown -R node:node /app
+RUN chown -R 1000:1000 /data
+`), "Owner set")
+	if len(suggestions) != 2 {
+		t.Errorf("Expected the owner errors of both RUN instructions but they were %v", suggestions)
+	}
+}
@@ -0,0 +1,59 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStageNamesAreNotAnalyzedAsImages(t *testing.T) {
+	suggestions := analyzeContainerfile(t, "FROM scratch AS build\nFROM build AS final\n")
+	if errors := findResults(suggestions, "Analyze error"); len(errors) != 0 {
+		t.Errorf("Expected the stage names not to be analyzed as images but it was %v", errors)
+	}
+}
+
+func TestStageStartsWithUserOfPreviousStage(t *testing.T) {
+	suggestions := findResults(analyzeContainerfile(t, `FROM scratch AS build
+USER 1001
+FROM build
+RUN dnf install -y git
+`), "Package manager used as non-root user")
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0].Description, "as user 1001") {
+		t.Errorf("Expected the stage based on build to run as user 1001 but it was %v", suggestions)
+	}
+}
+
+func TestStageStartsAsRootFromImage(t *testing.T) {
+	suggestions := analyzeContainerfile(t, `FROM scratch AS build
+USER 1001
+FROM scratch
+RUN dnf install -y git
+`)
+	if packages := findResults(suggestions, "Package manager used as non-root user"); len(packages) != 0 {
+		t.Errorf("Expected the stage based on an image to start as root but it was %v", packages)
+	}
+}
+
+func TestAnalyzeErrorOfEveryBaseImage(t *testing.T) {
+	suggestions := analyzeContainerfile(t, "FROM oci:/nonexistent/first:1.0 AS first\nFROM oci:/nonexistent/second:1.0\n")
+	if errors := findResults(suggestions, "Analyze error"); len(errors) != 2 {
+		t.Errorf("Expected the analyze errors of both base images but they were %v", errors)
+	}
+}
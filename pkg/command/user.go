@@ -30,9 +30,11 @@ type User struct{}
 
 type userResultKeyType struct{}
 type userProcessedKeyType struct{}
+type userCurrentKeyType struct{}
 
 var userResultKey userResultKeyType
 var userProcessedKey userProcessedKeyType
+var userCurrentKey userCurrentKeyType
 
 func (u User) Analyze(ctx context.Context, node *parser.Node, source utils.Source, line Line) context.Context {
 	var results []Result
@@ -45,6 +47,7 @@ func (u User) Analyze(ctx context.Context, node *parser.Node, source utils.Sourc
 		})
 	}
 	ctx = context.WithValue(ctx, userResultKey, results)
+	ctx = context.WithValue(ctx, userCurrentKey, node.Value)
 	return context.WithValue(ctx, userProcessedKey, true)
 }
 
@@ -66,3 +69,17 @@ func (u User) PostProcess(ctx context.Context) []Result {
 	return results
 
 }
+
+// GetCurrentUser returns the user set by the last USER directive analyzed, an empty string means root
+func GetCurrentUser(ctx context.Context) string {
+	user := ctx.Value(userCurrentKey)
+	if user == nil {
+		return ""
+	}
+	return user.(string)
+}
+
+func IsRootUser(user string) bool {
+	name := strings.SplitN(user, ":", 2)[0]
+	return name == "" || name == "0" || strings.EqualFold(name, "root")
+}
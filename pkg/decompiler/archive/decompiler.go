@@ -0,0 +1,110 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// DockerArchiveProvider handles the docker-archive:/path/to/archive.tar[:name:tag] references,
+// i.e. tarballs created by docker save or podman save
+type DockerArchiveProvider struct {
+	// Platform is the platform the image must match, any platform is accepted if nil
+	Platform *v1.Platform
+}
+
+func (p DockerArchiveProvider) Name() string {
+	return "docker-archive"
+}
+
+func (p DockerArchiveProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	if !strings.HasPrefix(imageName, utils.DOCKER_ARCHIVE_TRANSPORT) {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, reference := decompilerutils.SplitReference(strings.TrimPrefix(imageName, utils.DOCKER_ARCHIVE_TRANSPORT))
+	var tag *name.Tag
+	if reference != "" {
+		t, err := name.NewTag(reference)
+		if err != nil {
+			return nil, err
+		}
+		tag = &t
+	}
+
+	img, err := tarball.ImageFromPath(path, tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read the archive %s", path)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if err := decompilerutils.CheckPlatform(p.Platform, imageName, decompilerutils.ConfigPlatform(configFile)); err != nil {
+		return nil, err
+	}
+
+	return decompilerutils.ImageToNode(img)
+}
+
+// OCIArchiveProvider handles the oci-archive:/path/to/archive.tar[:tag] references,
+// i.e. tarballs of an OCI layout directory created by podman save --format oci-archive
+type OCIArchiveProvider struct {
+	// Platform is the platform to select in a multi-architecture index, the default one is selected if nil
+	Platform *v1.Platform
+}
+
+func (p OCIArchiveProvider) Name() string {
+	return "oci-archive"
+}
+
+func (p OCIArchiveProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	if !strings.HasPrefix(imageName, utils.OCI_ARCHIVE_TRANSPORT) {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, tag := decompilerutils.SplitReference(strings.TrimPrefix(imageName, utils.OCI_ARCHIVE_TRANSPORT))
+
+	index, err := readArchiveIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	img, platforms, err := decompilerutils.ImageFromIndex(index, path, tag, p.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := decompilerutils.ImageToNode(img)
+	if err != nil {
+		return nil, err
+	}
+	image.Platforms = platforms
+	return image, nil
+}
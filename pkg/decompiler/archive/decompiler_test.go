@@ -0,0 +1,173 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+)
+
+func testImage(t *testing.T) v1.Image {
+	return testutil.NewImage(t, &v1.ConfigFile{
+		Config: v1.Config{
+			User: "1001",
+		},
+		History: []v1.History{
+			{CreatedBy: "/bin/sh -c #(nop) EXPOSE 80/tcp", EmptyLayer: true},
+		},
+	})
+}
+
+func TestDecompileDockerArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.tar")
+	tag, _ := name.NewTag("localhost/app:1.0")
+	if err := tarball.WriteToFile(path, tag, testImage(t)); err != nil {
+		t.Fatal(err)
+	}
+	for _, reference := range []string{"docker-archive:" + path, "docker-archive:" + path + ":localhost/app:1.0"} {
+		image, err := DockerArchiveProvider{}.Decompile(context.Background(), reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(image.Node.Children) != 2 || !strings.EqualFold(image.Node.Children[0].Value, "expose") || !strings.EqualFold(image.Node.Children[1].Value, "user") {
+			t.Errorf("Expected EXPOSE and USER instructions but it was %s", image.Node.Dump())
+		}
+	}
+	if _, err := (DockerArchiveProvider{}).Decompile(context.Background(), "docker-archive:"+path+":localhost/other:1.0"); err == nil {
+		t.Error("Expected an error when the image is not in the archive")
+	}
+}
+
+func TestDecompileOCIArchive(t *testing.T) {
+	dir := testutil.WriteImageLayout(t, "1.0", testImage(t))
+	path := filepath.Join(t.TempDir(), "app.tar")
+	writeTar(t, dir, path)
+
+	image, err := OCIArchiveProvider{}.Decompile(context.Background(), "oci-archive:"+path+":1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(image.Node.Children) != 2 || !strings.EqualFold(image.Node.Children[0].Value, "expose") || !strings.EqualFold(image.Node.Children[1].Value, "user") {
+		t.Errorf("Expected EXPOSE and USER instructions but it was %s", image.Node.Dump())
+	}
+}
+
+func TestDecompileOCIArchiveReadsOnlyMetadata(t *testing.T) {
+	layer, err := random.Layer(1024, types.OCILayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(testImage(t), layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := testutil.NewLayout(t)
+	testutil.AppendIndex(t, p, "1.0", testutil.NewIndex(t, img))
+	dir := string(p)
+	digest, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := layer.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "app.tar")
+	// the layer is missing from the archive, it must not be read
+	writeTar(t, dir, path, "blobs/sha256/"+digest.Hex)
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	image, err := OCIArchiveProvider{}.Decompile(context.Background(), "oci-archive:"+path+":1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(image.LayerSizes) != 1 || image.LayerSizes[0] != size {
+		t.Errorf("Expected a layer of %d bytes but it was %v", size, image.LayerSizes)
+	}
+	if strings.Join(image.Platforms, ",") != "linux/amd64" {
+		t.Errorf("Expected the linux/amd64 platform but it was %v", image.Platforms)
+	}
+	if entries, err := os.ReadDir(tmp); err != nil || len(entries) != 0 {
+		t.Errorf("Expected the archive not to be extracted but it was %v, %v", entries, err)
+	}
+}
+
+func TestDecompileArchiveIgnoresOtherReferences(t *testing.T) {
+	for _, reference := range []string{"docker.io/nginx:1.25.3", "oci:/tmp/layout"} {
+		if image, err := (DockerArchiveProvider{}).Decompile(context.Background(), reference); image != nil || err != nil {
+			t.Errorf("Expected %s to be ignored but it was %v, %v", reference, image, err)
+		}
+		if image, err := (OCIArchiveProvider{}).Decompile(context.Background(), reference); image != nil || err != nil {
+			t.Errorf("Expected %s to be ignored but it was %v, %v", reference, image, err)
+		}
+	}
+}
+
+// writeTar writes the files of dir to the tarball at path, except the skipped ones
+func writeTar(t *testing.T, dir string, path string, skipped ...string) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	writer := tar.NewWriter(file)
+	defer writer.Close()
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		for _, skip := range skipped {
+			if filepath.ToSlash(rel) == skip {
+				return nil
+			}
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(writer, in)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,168 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+)
+
+// archiveIndex is an OCI layout read from the tar stream of an oci-archive. Only index.json and the blobs
+// of the manifests and configs are read, the layers are never extracted
+type archiveIndex struct {
+	path     string
+	raw      []byte
+	manifest *v1.IndexManifest
+}
+
+func newArchiveIndex(archive string, raw []byte) (*archiveIndex, error) {
+	manifest, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return &archiveIndex{path: archive, raw: raw, manifest: manifest}, nil
+}
+
+// readArchiveIndex returns the top-level index of the oci-archive at path
+func readArchiveIndex(archive string) (*archiveIndex, error) {
+	raw, err := readArchiveFile(archive, "index.json")
+	if err != nil {
+		return nil, err
+	}
+	index, err := newArchiveIndex(archive, raw)
+	return index, errors.Wrapf(err, "unable to read the archive %s", archive)
+}
+
+// readArchiveFile returns the content of the file at name in the archive, the other entries are skipped
+func readArchiveFile(archive string, name string) ([]byte, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, errors.Errorf("unable to find %s in the archive %s", name, archive)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && path.Clean("/"+header.Name) == "/"+name {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+func (i *archiveIndex) readBlob(digest v1.Hash) ([]byte, error) {
+	return readArchiveFile(i.path, path.Join("blobs", digest.Algorithm, digest.Hex))
+}
+
+func (i *archiveIndex) descriptor(digest v1.Hash) (*v1.Descriptor, error) {
+	for j, desc := range i.manifest.Manifests {
+		if desc.Digest == digest {
+			return &i.manifest.Manifests[j], nil
+		}
+	}
+	return nil, errors.Errorf("unable to find %s in the archive %s", digest, i.path)
+}
+
+func (i *archiveIndex) MediaType() (types.MediaType, error) {
+	if i.manifest.MediaType != "" {
+		return i.manifest.MediaType, nil
+	}
+	return types.OCIImageIndex, nil
+}
+
+func (i *archiveIndex) Digest() (v1.Hash, error) {
+	digest, _, err := v1.SHA256(bytes.NewReader(i.raw))
+	return digest, err
+}
+
+func (i *archiveIndex) Size() (int64, error) {
+	return int64(len(i.raw)), nil
+}
+
+func (i *archiveIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return i.manifest, nil
+}
+
+func (i *archiveIndex) RawManifest() ([]byte, error) {
+	return i.raw, nil
+}
+
+func (i *archiveIndex) Image(digest v1.Hash) (v1.Image, error) {
+	desc, err := i.descriptor(digest)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := i.readBlob(digest)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return partial.CompressedToImage(&archiveImage{index: i, mediaType: desc.MediaType, raw: raw, manifest: manifest})
+}
+
+func (i *archiveIndex) ImageIndex(digest v1.Hash) (v1.ImageIndex, error) {
+	if _, err := i.descriptor(digest); err != nil {
+		return nil, err
+	}
+	raw, err := i.readBlob(digest)
+	if err != nil {
+		return nil, err
+	}
+	return newArchiveIndex(i.path, raw)
+}
+
+// archiveImage is an image of an oci-archive, only its manifest and config can be read
+type archiveImage struct {
+	index     *archiveIndex
+	mediaType types.MediaType
+	raw       []byte
+	manifest  *v1.Manifest
+}
+
+func (i *archiveImage) MediaType() (types.MediaType, error) {
+	return i.mediaType, nil
+}
+
+func (i *archiveImage) RawManifest() ([]byte, error) {
+	return i.raw, nil
+}
+
+func (i *archiveImage) RawConfigFile() ([]byte, error) {
+	return i.index.readBlob(i.manifest.Config.Digest)
+}
+
+func (i *archiveImage) LayerByDigest(digest v1.Hash) (partial.CompressedLayer, error) {
+	return nil, errors.Errorf("the layer %s of the archive %s is not read", digest, i.index.path)
+}
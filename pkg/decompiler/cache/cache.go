@@ -0,0 +1,301 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+// DEFAULT_TTL is how long a tag resolution is reused by default before the registry is contacted again
+const DEFAULT_TTL = time.Hour
+
+// FORMAT_VERSION is the version of the format of the cached images, the images cached with another version are ignored
+const FORMAT_VERSION = 1
+
+// Cache stores the images decompiled from registries, keyed by manifest digest, the digests their
+// references were resolved to and the findings of the parent images. A nil cache stores nothing
+type Cache struct {
+	Dir string
+	// TTL is how long the resolution of a tag is reused, the resolution of a digest never expires
+	TTL time.Duration
+}
+
+// cachedImage is the stored form of a decompiled image
+type cachedImage struct {
+	Version       int
+	Node          *parser.Node
+	Digest        string
+	Platform      string
+	Platforms     []string
+	LayerSizes    []int64
+	DiffIDs       []string
+	HistoryLength int
+	// History is the history entry of each instruction of Node, nil for the instructions rebuilt from the image config
+	History []*decompilerutils.HistoryEntry
+}
+
+// findings are the findings of a parent image, keyed by its digest and the state of the analysis they start from
+type findings struct {
+	Version  int
+	Key      string
+	Findings json.RawMessage
+}
+
+// resolution is the digest of the image a reference was resolved to
+type resolution struct {
+	Key    string
+	Digest string
+	// Pinned is set if the reference is a digest, its resolution never expires
+	Pinned   bool
+	Resolved time.Time
+}
+
+// Default returns the cache in the user cache directory ($XDG_CACHE_HOME/doa on Linux), nil if there is none
+func Default(ttl time.Duration) *Cache {
+	dir := DefaultDir()
+	if dir == "" {
+		return nil
+	}
+	return New(dir, ttl)
+}
+
+// DefaultDir returns the directory of the cache and of the catalogue of analyzed images in the user cache
+// directory, empty if there is none
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "doa")
+}
+
+// New returns the cache in dir, the tag resolutions are reused for DEFAULT_TTL if ttl is not positive
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DEFAULT_TTL
+	}
+	return &Cache{
+		Dir: dir,
+		TTL: ttl,
+	}
+}
+
+func (c *Cache) imagesDir() string {
+	return filepath.Join(c.Dir, "images")
+}
+
+func (c *Cache) resolutionsDir() string {
+	return filepath.Join(c.Dir, "resolutions")
+}
+
+func (c *Cache) findingsDir() string {
+	return filepath.Join(c.Dir, "findings")
+}
+
+func (c *Cache) imagePath(digest string) string {
+	return filepath.Join(c.imagesDir(), strings.ReplaceAll(digest, ":", "-")+".json")
+}
+
+func (c *Cache) resolutionPath(key string) string {
+	return hashedPath(c.resolutionsDir(), key)
+}
+
+func (c *Cache) findingsPath(key string) string {
+	return hashedPath(c.findingsDir(), key)
+}
+
+func hashedPath(dir string, key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(hash[:])+".json")
+}
+
+// Image returns the decompiled image of a manifest digest, nil if it is not cached
+func (c *Cache) Image(digest string) *decompilerutils.Image {
+	if c == nil || digest == "" {
+		return nil
+	}
+	path := c.imagePath(digest)
+	var cached cachedImage
+	if err := readJSON(path, &cached); err != nil || cached.Version != FORMAT_VERSION || cached.Node == nil ||
+		len(cached.History) != len(cached.Node.Children) {
+		return nil
+	}
+	// the images last used are kept when the cache is pruned
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	image := &decompilerutils.Image{
+		Node:          cached.Node,
+		Digest:        cached.Digest,
+		Platform:      cached.Platform,
+		Platforms:     cached.Platforms,
+		LayerSizes:    cached.LayerSizes,
+		DiffIDs:       cached.DiffIDs,
+		HistoryLength: cached.HistoryLength,
+		History:       map[*parser.Node]decompilerutils.HistoryEntry{},
+	}
+	for i, entry := range cached.History {
+		if entry != nil {
+			image.History[cached.Node.Children[i]] = *entry
+		}
+	}
+	return image
+}
+
+// StoreImage caches a decompiled image under its manifest digest
+func (c *Cache) StoreImage(image *decompilerutils.Image) error {
+	if c == nil || image.Digest == "" {
+		return nil
+	}
+	cached := cachedImage{
+		Version:       FORMAT_VERSION,
+		Node:          image.Node,
+		Digest:        image.Digest,
+		Platform:      image.Platform,
+		Platforms:     image.Platforms,
+		LayerSizes:    image.LayerSizes,
+		DiffIDs:       image.DiffIDs,
+		HistoryLength: image.HistoryLength,
+	}
+	for _, child := range image.Node.Children {
+		var entry *decompilerutils.HistoryEntry
+		if historyEntry, ok := image.History[child]; ok {
+			entry = &historyEntry
+		}
+		cached.History = append(cached.History, entry)
+	}
+	return utils.WriteJSON(c.imagePath(image.Digest), cached)
+}
+
+// Resolution returns the digest a reference was resolved to, an empty string if it is not cached or the
+// resolution of a tag is older than the TTL
+func (c *Cache) Resolution(key string) string {
+	if c == nil {
+		return ""
+	}
+	var resolved resolution
+	if err := readJSON(c.resolutionPath(key), &resolved); err != nil || resolved.Key != key || c.expired(resolved) {
+		return ""
+	}
+	return resolved.Digest
+}
+
+// StoreResolution caches the digest a reference was resolved to
+func (c *Cache) StoreResolution(key string, digest string, pinned bool) error {
+	if c == nil || digest == "" {
+		return nil
+	}
+	return utils.WriteJSON(c.resolutionPath(key), resolution{
+		Key:      key,
+		Digest:   digest,
+		Pinned:   pinned,
+		Resolved: time.Now(),
+	})
+}
+
+// Findings reads into value the findings of a parent image stored under key, it returns false if they are not cached
+func (c *Cache) Findings(key string, value interface{}) bool {
+	if c == nil {
+		return false
+	}
+	path := c.findingsPath(key)
+	var cached findings
+	if err := readJSON(path, &cached); err != nil || cached.Version != FORMAT_VERSION || cached.Key != key ||
+		json.Unmarshal(cached.Findings, value) != nil {
+		return false
+	}
+	// the findings last used are kept when the cache is pruned
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return true
+}
+
+// StoreFindings caches the findings of a parent image under key, which identifies the image and the state
+// of the analysis they depend on
+func (c *Cache) StoreFindings(key string, value interface{}) error {
+	if c == nil {
+		return nil
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return utils.WriteJSON(c.findingsPath(key), findings{
+		Version:  FORMAT_VERSION,
+		Key:      key,
+		Findings: content,
+	})
+}
+
+func (c *Cache) expired(resolved resolution) bool {
+	return !resolved.Pinned && time.Since(resolved.Resolved) > c.TTL
+}
+
+// Prune removes the expired tag resolutions and the images, findings and digest resolutions unused for longer than maxAge,
+// everything if maxAge is 0. It returns the number of entries removed
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	removed := 0
+	for _, dir := range []string{c.resolutionsDir(), c.imagesDir(), c.findingsDir()} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return removed, err
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			prune := maxAge == 0 || time.Since(info.ModTime()) > maxAge
+			if !prune && dir == c.resolutionsDir() {
+				var resolved resolution
+				prune = readJSON(path, &resolved) != nil || c.expired(resolved)
+			}
+			if prune {
+				if err := os.Remove(path); err != nil {
+					return removed, err
+				}
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+func readJSON(path string, value interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, value)
+}
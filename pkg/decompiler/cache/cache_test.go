@@ -0,0 +1,149 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+)
+
+func newTestImage(t *testing.T) *decompilerutils.Image {
+	result, err := parser.Parse(strings.NewReader("FROM scratch\nUSER 1001\nEXPOSE 8080\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &decompilerutils.Image{
+		Node:       result.AST,
+		Digest:     "sha256:0123",
+		Platform:   "linux/amd64",
+		LayerSizes: []int64{42},
+		History: map[*parser.Node]decompilerutils.HistoryEntry{
+			result.AST.Children[1]: {Index: 1, CreatedBy: "USER 1001", EmptyLayer: true, Layer: -1},
+		},
+	}
+}
+
+func TestImageRoundTrip(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	if image := c.Image("sha256:0123"); image != nil {
+		t.Fatal("Expected no image in an empty cache")
+	}
+	if err := c.StoreImage(newTestImage(t)); err != nil {
+		t.Fatal(err)
+	}
+	image := c.Image("sha256:0123")
+	if image == nil {
+		t.Fatal("Expected the stored image to be cached")
+	}
+	if len(image.Node.Children) != 3 || image.Platform != "linux/amd64" || len(image.LayerSizes) != 1 {
+		t.Fatalf("Expected the stored image but it was %+v", image)
+	}
+	entry, ok := image.History[image.Node.Children[1]]
+	if !ok || entry.CreatedBy != "USER 1001" {
+		t.Errorf("Expected the history entry of USER but the history was %v", image.History)
+	}
+	if _, ok := image.History[image.Node.Children[2]]; ok {
+		t.Error("Expected no history entry for EXPOSE")
+	}
+}
+
+func TestResolutionTTL(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	if err := c.StoreResolution("app:1.0", "sha256:0123", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StoreResolution("app@sha256:0123", "sha256:0123", true); err != nil {
+		t.Fatal(err)
+	}
+	if digest := c.Resolution("app:1.0"); digest != "sha256:0123" {
+		t.Errorf("Expected the tag to be resolved to sha256:0123 but it was %q", digest)
+	}
+
+	c.TTL = -time.Second
+	if digest := c.Resolution("app:1.0"); digest != "" {
+		t.Errorf("Expected the resolution of the tag to be expired but it was %q", digest)
+	}
+	if digest := c.Resolution("app@sha256:0123"); digest != "sha256:0123" {
+		t.Errorf("Expected the resolution of the digest to never expire but it was %q", digest)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: -time.Second}
+	if err := c.StoreImage(newTestImage(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StoreResolution("app:1.0", "sha256:0123", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StoreResolution("app@sha256:0123", "sha256:0123", true); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := c.Prune(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || c.Image("sha256:0123") == nil || c.Resolution("app@sha256:0123") == "" {
+		t.Errorf("Expected only the expired tag resolution to be removed but %d entries were", removed)
+	}
+
+	if removed, err = c.Prune(0); err != nil || removed != 2 {
+		t.Errorf("Expected the 2 remaining entries to be removed but %d were: %v", removed, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(c.Dir, "images"))
+	if len(entries) != 0 {
+		t.Errorf("Expected no cached image left but there were %d", len(entries))
+	}
+}
+
+func TestFindingsRoundTrip(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	var descriptions []string
+	if c.Findings("sha256:0123 root", &descriptions) {
+		t.Fatal("Expected no findings in an empty cache")
+	}
+	if err := c.StoreFindings("sha256:0123 root", []string{"port 80 exposed"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Findings("sha256:0123 root", &descriptions) || len(descriptions) != 1 || descriptions[0] != "port 80 exposed" {
+		t.Errorf("Expected the stored findings to be cached but they were %v", descriptions)
+	}
+	if c.Findings("sha256:0123 1001", &descriptions) {
+		t.Error("Expected no findings for another state of the analysis")
+	}
+
+	if removed, err := c.Prune(0); err != nil || removed != 1 {
+		t.Errorf("Expected the findings to be removed but %d entries were: %v", removed, err)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	if err := c.StoreImage(newTestImage(t)); err != nil {
+		t.Error(err)
+	}
+	if c.Image("sha256:0123") != nil || c.Resolution("app:1.0") != "" {
+		t.Error("Expected a nil cache to hold nothing")
+	}
+}
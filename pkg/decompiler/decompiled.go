@@ -0,0 +1,159 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// DecompiledInstruction is an instruction rebuilt from an image
+type DecompiledInstruction struct {
+	Instruction string `json:"instruction"`
+	// HistoryIndex is the index of the history entry the instruction was rebuilt from, nil if rebuilt from the image config
+	HistoryIndex *int       `json:"historyIndex,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+	// Size is the size of the layer created by the instruction, if known
+	Size       *int64            `json:"size,omitempty"`
+	EmptyLayer bool              `json:"emptyLayer,omitempty"`
+	BuildArgs  map[string]string `json:"buildArgs,omitempty"`
+}
+
+// DecompiledImage is the Containerfile rebuilt from an image
+type DecompiledImage struct {
+	Image        string                  `json:"image"`
+	Provider     string                  `json:"provider"`
+	Digest       string                  `json:"digest,omitempty"`
+	Reference    string                  `json:"reference,omitempty"`
+	Platform     string                  `json:"platform,omitempty"`
+	Instructions []DecompiledInstruction `json:"instructions"`
+}
+
+// NewDecompiledImage lists the instructions rebuilt from an image with the history entry they come from
+func NewDecompiledImage(imageName string, image *Image) DecompiledImage {
+	decompiled := DecompiledImage{
+		Image:        imageName,
+		Provider:     image.Provider,
+		Digest:       image.Digest,
+		Reference:    image.Reference,
+		Platform:     image.Platform,
+		Instructions: []DecompiledInstruction{},
+	}
+	for _, child := range image.Node.Children {
+		instruction := DecompiledInstruction{
+			Instruction: instructionLine(child),
+		}
+		if entry, ok := image.History[child]; ok {
+			index := entry.Index
+			instruction.HistoryIndex = &index
+			if !entry.Created.IsZero() {
+				created := entry.Created
+				instruction.Created = &created
+			}
+			if entry.Layer >= 0 && entry.Layer < len(image.LayerSizes) {
+				size := image.LayerSizes[entry.Layer]
+				instruction.Size = &size
+			}
+			instruction.EmptyLayer = entry.EmptyLayer
+			instruction.BuildArgs = entry.BuildArgs
+		}
+		decompiled.Instructions = append(decompiled.Instructions, instruction)
+	}
+	return decompiled
+}
+
+// Containerfile renders the instructions, preceded by comments describing the history entry they come from
+func (d DecompiledImage) Containerfile() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Decompiled from %s by %s\n", d.Image, d.Provider)
+	for _, detail := range []struct {
+		name  string
+		value string
+	}{
+		{"Reference", d.Reference},
+		{"Digest", d.Digest},
+		{"Platform", d.Platform},
+	} {
+		if detail.value != "" {
+			fmt.Fprintf(&builder, "# %s: %s\n", detail.name, detail.value)
+		}
+	}
+
+	previous := ""
+	for _, instruction := range d.Instructions {
+		// instructions rebuilt from the same history entry share its comment
+		if comment := instruction.comment(); comment != previous {
+			fmt.Fprintf(&builder, "\n%s\n", comment)
+			previous = comment
+		}
+		builder.WriteString(instruction.Instruction + "\n")
+	}
+	return builder.String()
+}
+
+func (i DecompiledInstruction) comment() string {
+	if i.HistoryIndex == nil {
+		return "# image config"
+	}
+	details := []string{fmt.Sprintf("# history %d", *i.HistoryIndex)}
+	if i.EmptyLayer {
+		details = append(details, "empty layer")
+	} else if i.Size != nil {
+		details = append(details, formatSize(*i.Size))
+	}
+	if i.Created != nil {
+		details = append(details, "created "+i.Created.UTC().Format(time.RFC3339))
+	}
+	if len(i.BuildArgs) > 0 {
+		var buildArgs []string
+		for name, value := range i.BuildArgs {
+			buildArgs = append(buildArgs, name+"="+value)
+		}
+		sort.Strings(buildArgs)
+		details = append(details, "build args "+strings.Join(buildArgs, " "))
+	}
+	return strings.Join(details, ", ")
+}
+
+// instructionLine returns the text of an instruction, as parsed or rebuilt from its arguments
+func instructionLine(node *parser.Node) string {
+	if node.Original != "" {
+		return node.Original
+	}
+	line := append([]string{strings.ToUpper(node.Value)}, node.Flags...)
+	for next := node.Next; next != nil; next = next.Next {
+		line = append(line, next.Value)
+	}
+	return strings.Join(line, " ")
+}
+
+// formatSize returns a size in decimal units, as docker and podman print them
+func formatSize(size int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.4g%s", value, units[unit])
+}
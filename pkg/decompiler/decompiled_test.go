@@ -0,0 +1,120 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+)
+
+func writeDecompileLayout(t *testing.T) string {
+	layer, err := random.Layer(1024, "application/vnd.oci.image.layer.v1.tar+gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Created:   v1.Time{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+			CreatedBy: "RUN |1 VERSION=1.0 /bin/sh -c chmod 700 /app # buildkit",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile = configFile.DeepCopy()
+	configFile.OS = "linux"
+	configFile.Architecture = "amd64"
+	configFile.History = append(configFile.History, v1.History{CreatedBy: "USER 1001", EmptyLayer: true})
+	configFile.Config.User = "1001"
+	configFile.Config.Cmd = []string{"/app/run"}
+	if img, err = mutate.ConfigFile(img, configFile); err != nil {
+		t.Fatal(err)
+	}
+	return testutil.WriteImageLayout(t, "1.0", img)
+}
+
+func TestNewDecompiledImage(t *testing.T) {
+	imageName := "oci:" + writeDecompileLayout(t)
+	image, err := Decompile(context.Background(), imageName, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompiled := NewDecompiledImage(imageName, image)
+	if decompiled.Provider != "oci-layout" || !strings.HasPrefix(decompiled.Digest, "sha256:") {
+		t.Errorf("Expected the provider and digest of the image but they were %s and %s", decompiled.Provider, decompiled.Digest)
+	}
+	expected := []string{"RUN chmod 700 /app", "USER 1001", `CMD ["/app/run"]`}
+	if len(decompiled.Instructions) != len(expected) {
+		t.Fatalf("Expected %d instructions but they were %+v", len(expected), decompiled.Instructions)
+	}
+	for i, instruction := range decompiled.Instructions {
+		if instruction.Instruction != expected[i] {
+			t.Errorf("Expected instruction %d to be %s but it was %s", i, expected[i], instruction.Instruction)
+		}
+	}
+	run := decompiled.Instructions[0]
+	if run.HistoryIndex == nil || *run.HistoryIndex != 0 || run.Size == nil || *run.Size == 0 || run.BuildArgs["VERSION"] != "1.0" {
+		t.Errorf("Expected the history entry, layer size and build arguments of the RUN instruction but it was %+v", run)
+	}
+	if user := decompiled.Instructions[1]; user.HistoryIndex == nil || *user.HistoryIndex != 1 || !user.EmptyLayer || user.Size != nil {
+		t.Errorf("Expected the USER instruction to be an empty layer history entry but it was %+v", user)
+	}
+	if cmd := decompiled.Instructions[2]; cmd.HistoryIndex != nil {
+		t.Errorf("Expected the CMD instruction to be rebuilt from the image config but it was %+v", cmd)
+	}
+
+	containerfile := decompiled.Containerfile()
+	for _, line := range []string{
+		"# Decompiled from " + imageName + " by oci-layout",
+		"# history 0, ",
+		", created 2026-01-01T00:00:00Z, build args VERSION=1.0\nRUN chmod 700 /app\n",
+		"# history 1, empty layer\nUSER 1001\n",
+		"# image config\nCMD [\"/app/run\"]\n",
+	} {
+		if !strings.Contains(containerfile, line) {
+			t.Errorf("Expected %q in the Containerfile but it was\n%s", line, containerfile)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, expected := range map[int64]string{
+		0:          "0B",
+		999:        "999B",
+		1500:       "1.5kB",
+		12345678:   "12.35MB",
+		3000000000: "3GB",
+	} {
+		if formatted := formatSize(size); formatted != expected {
+			t.Errorf("Expected %d to be formatted as %s but it was %s", size, expected, formatted)
+		}
+	}
+}
@@ -18,31 +18,296 @@
  package decompiler
 
 import (
-	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/pkg/errors"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/catalogue"
+	archive "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/archive"
+	cache "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/cache"
 	docker "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/docker"
+	layout "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/layout"
 	podman "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/podman"
 	registry "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/registry"
+	storage "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/storage"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
 )
 
 type Provider interface {
-	Decompile(imageName string) (*parser.Node, error)
+	// Name identifies the provider in the results
+	Name() string
+	// Decompile returns a nil image if the image name is not handled by the provider
+	Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error)
+}
+
+type Image = decompilerutils.Image
+
+// ProviderError is the reason why a provider was unable to decompile an image
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e ProviderError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Provider, e.Err)
+}
+
+func (e ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ResolveError is returned when no provider was able to decompile an image, with the error of each provider tried
+type ResolveError struct {
+	ImageName string
+	Errors    []ProviderError
+}
+
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("Can't resolve image %s", e.ImageName)
+}
+
+const (
+	SourceAuto      = "auto"
+	SourcePodman    = "podman"
+	SourceDocker    = "docker"
+	SourceRegistry  = "registry"
+	SourceOCILayout = "oci-layout"
+	SourceArchive   = "archive"
+)
+
+// Sources lists the values accepted to select where images are looked up
+var Sources = []string{SourceAuto, SourcePodman, SourceDocker, SourceRegistry, SourceOCILayout, SourceArchive}
+
+// Options configures how the providers look up the images
+type Options struct {
+	// Connection is the name of the Podman connection to use, as listed by podman system connection list
+	Connection string
+	// Source restricts the providers used to look up the images, all of them are tried in order if empty or auto
+	Source string
+	// RegistriesConf is the path of registries.conf, looked up as podman does if empty
+	RegistriesConf string
+	// AuthFile is the path of the registry credentials file, looked up as podman does if empty
+	AuthFile string
+	// Credentials are the username[:password] used for all the registries
+	Credentials string
+	// CertDir is the directory holding the certificates used for all the registries, instead of their certs.d directory
+	CertDir string
+	// InsecureSkipTLSVerify allows plain HTTP and skips the verification of the certificates for all the registries
+	InsecureSkipTLSVerify bool
+	// Platform is the os/arch[/variant] selected in multi-architecture images and that local images must match.
+	// Linux on the current architecture is selected if empty, and local images are not checked
+	Platform string
+	// NoCache disables the cache of the images decompiled from registries and of the findings of the parent images,
+	// and the catalogue of analyzed images
+	NoCache bool
+	// CacheDir is the directory of the cache and of the catalogue, the doa directory of the user cache directory if empty
+	CacheDir string
+	// CacheTTL is how long the digest a tag was resolved to is reused, cache.DEFAULT_TTL if 0
+	CacheTTL time.Duration
+	// Offline restricts the images to the local ones and those cached from registries, no registry is contacted
+	Offline bool
+}
+
+// Cache returns the cache of the images decompiled from registries and of the findings of the parent images,
+// nil if it is disabled
+func (o Options) Cache() *cache.Cache {
+	dir := o.cacheDir()
+	if o.NoCache || dir == "" {
+		return nil
+	}
+	c := cache.New(dir, o.CacheTTL)
+	if o.Offline {
+		// the tags can't be resolved again, the last resolution is used
+		c.TTL = time.Duration(math.MaxInt64)
+	}
+	return c
+}
+
+// Catalogue returns the catalogue of the images analyzed so far, an empty one which records nothing if it is disabled
+func (o Options) Catalogue() catalogue.Catalogue {
+	dir := o.cacheDir()
+	if o.NoCache || dir == "" {
+		return catalogue.Catalogue{}
+	}
+	return catalogue.New(dir)
+}
+
+func (o Options) cacheDir() string {
+	if o.CacheDir != "" {
+		return o.CacheDir
+	}
+	return cache.DefaultDir()
+}
+
+// systemContext returns the containers configuration used to reach the registries
+func (o Options) systemContext() (*types.SystemContext, error) {
+	sys := &types.SystemContext{
+		SystemRegistriesConfPath: o.RegistriesConf,
+		AuthFilePath:             o.AuthFile,
+		DockerCertPath:           o.CertDir,
+	}
+	if o.InsecureSkipTLSVerify {
+		sys.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
+	if o.Credentials != "" {
+		username, password, _ := strings.Cut(o.Credentials, ":")
+		if username == "" {
+			return nil, errors.New("credentials must be set as username[:password]")
+		}
+		sys.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: username,
+			Password: password,
+		}
+	}
+	return sys, nil
 }
 
-func Decompile(imageName string) (*parser.Node, error) {
-	providers := []Provider{
-		podman.PodmanProvider{},
-		docker.DockerProvider{},
-		registry.RegistryProvider{},
+// getProviders returns the providers to use for the selected source, in the order they are tried.
+// In auto mode, references with a transport (e.g. oci:) are only handled by the provider of the transport
+func getProviders(imageName string, options Options) ([]Provider, error) {
+	sys, err := options.systemContext()
+	if err != nil {
+		return nil, err
+	}
+	var platform *v1.Platform
+	if options.Platform != "" {
+		if platform, err = v1.ParsePlatform(options.Platform); err != nil {
+			return nil, errors.Wrapf(err, "invalid platform %s", options.Platform)
+		}
+	}
+	layoutProvider := layout.LayoutProvider{
+		Platform: platform,
+	}
+	dockerArchiveProvider := archive.DockerArchiveProvider{
+		Platform: platform,
+	}
+	ociArchiveProvider := archive.OCIArchiveProvider{
+		Platform: platform,
+	}
+	dockerProvider := docker.DockerProvider{
+		Platform: platform,
+	}
+	registryProvider := registry.RegistryProvider{
+		SystemContext: sys,
+		Platform:      platform,
+		Cache:         options.Cache(),
+		Offline:       options.Offline,
+	}
+	podmanProviders := []Provider{
+		podman.PodmanProvider{
+			Connection: options.Connection,
+			Platform:   platform,
+		},
+		storage.StorageProvider{
+			SystemContext: sys,
+			Platform:      platform,
+		},
+	}
+	switch options.Source {
+	case "", SourceAuto:
+		switch {
+		case strings.HasPrefix(imageName, utils.OCI_LAYOUT_TRANSPORT):
+			return []Provider{layoutProvider}, nil
+		case strings.HasPrefix(imageName, utils.DOCKER_ARCHIVE_TRANSPORT):
+			return []Provider{dockerArchiveProvider}, nil
+		case strings.HasPrefix(imageName, utils.OCI_ARCHIVE_TRANSPORT):
+			return []Provider{ociArchiveProvider}, nil
+		}
+		return append(podmanProviders, dockerProvider, registryProvider), nil
+	case SourcePodman:
+		return podmanProviders, nil
+	case SourceDocker:
+		return []Provider{dockerProvider}, nil
+	case SourceRegistry:
+		return []Provider{registryProvider}, nil
+	case SourceOCILayout:
+		return []Provider{layoutProvider}, nil
+	case SourceArchive:
+		return []Provider{dockerArchiveProvider, ociArchiveProvider}, nil
+	}
+	return nil, errors.Errorf("unknown source %s, supported sources: %s", options.Source, strings.Join(Sources, ", "))
+}
+
+// Decompile tries the providers in order and returns the image decompiled by the first one finding it.
+// If none does, the returned *ResolveError holds the error of each provider. The remaining providers are not
+// tried once the context is done
+func Decompile(ctx context.Context, imageName string, options Options) (*Image, error) {
+	providers, err := getProviders(imageName, options)
+	if err != nil {
+		return nil, err
+	}
+	resolveErr := &ResolveError{
+		ImageName: imageName,
 	}
 	for _, provider := range providers {
-		node, err := provider.Decompile(imageName)
+		image, err := provider.Decompile(ctx, imageName)
+		if err != nil {
+			resolveErr.Errors = append(resolveErr.Errors, ProviderError{
+				Provider: provider.Name(),
+				Err:      err,
+			})
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if image != nil {
+			image.Provider = provider.Name()
+			return image, nil
+		}
+	}
+	return nil, resolveErr
+}
+
+// DefaultPlatform returns the platform selected if none is set, Linux on the current architecture
+func DefaultPlatform() string {
+	return decompilerutils.DefaultPlatform().String()
+}
+
+// DecompilePlatforms decompiles the image and, if it is selected from a multi-architecture index,
+// the images of the other platforms of the index with the same provider
+func DecompilePlatforms(ctx context.Context, imageName string, options Options) ([]*Image, error) {
+	image, err := Decompile(ctx, imageName, options)
+	if err != nil {
+		return nil, err
+	}
+	images := []*Image{image}
+	for _, platform := range image.Platforms {
+		if platform == image.Platform {
+			continue
+		}
+		platformOptions := options
+		platformOptions.Platform = platform
+		providers, err := getProviders(imageName, platformOptions)
 		if err != nil {
 			return nil, err
 		}
-		if node != nil {
-			return node, nil
+		for _, provider := range providers {
+			if provider.Name() != image.Provider {
+				continue
+			}
+			platformImage, err := provider.Decompile(ctx, imageName)
+			if err != nil && options.Offline {
+				// the images of the other platforms may not be cached
+				continue
+			} else if err != nil {
+				return nil, ProviderError{
+					Provider: provider.Name(),
+					Err:      err,
+				}
+			}
+			if platformImage == nil {
+				continue
+			}
+			platformImage.Provider = provider.Name()
+			images = append(images, platformImage)
 		}
 	}
-	return nil, errors.Errorf("Can't resolve image %s", imageName)
+	return images, nil
 }
@@ -0,0 +1,97 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProvidersOfSource(t *testing.T) {
+	expected := map[string][]string{
+		"":              {"podman", "containers-storage", "docker", "registry"},
+		SourceAuto:      {"podman", "containers-storage", "docker", "registry"},
+		SourcePodman:    {"podman", "containers-storage"},
+		SourceDocker:    {"docker"},
+		SourceRegistry:  {"registry"},
+		SourceOCILayout: {"oci-layout"},
+		SourceArchive:   {"docker-archive", "oci-archive"},
+	}
+	for source, names := range expected {
+		providers, err := getProviders("nginx", Options{Source: source})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(providers) != len(names) {
+			t.Fatalf("Expected %d providers for source %s but they were %d", len(names), source, len(providers))
+		}
+		for i, provider := range providers {
+			if provider.Name() != names[i] {
+				t.Errorf("Expected provider %s for source %s but it was %s", names[i], source, provider.Name())
+			}
+		}
+	}
+}
+
+func TestUnknownSource(t *testing.T) {
+	if _, err := Decompile(context.Background(), "nginx", Options{Source: "unknown"}); err == nil {
+		t.Error("Expected an error for an unknown source")
+	}
+}
+
+func TestProvidersOfTransport(t *testing.T) {
+	expected := map[string]string{
+		"oci:/tmp/layout:1.0":                "oci-layout",
+		"docker-archive:/tmp/nginx.tar":      "docker-archive",
+		"oci-archive:/tmp/nginx-oci.tar:1.0": "oci-archive",
+	}
+	for reference, name := range expected {
+		providers, err := getProviders(reference, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(providers) != 1 || providers[0].Name() != name {
+			t.Errorf("Expected only the %s provider for %s", name, reference)
+		}
+	}
+}
+
+func TestResolveErrorHoldsProviderErrors(t *testing.T) {
+	_, err := Decompile(context.Background(), "oci:/nonexistent/layout:1.0", Options{})
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("Expected a resolve error but it was %v", err)
+	}
+	if len(resolveErr.Errors) != 1 || resolveErr.Errors[0].Provider != "oci-layout" {
+		t.Errorf("Expected the error of the oci-layout provider but they were %v", resolveErr.Errors)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	sys, err := Options{Credentials: "user:pass:word"}.systemContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sys.DockerAuthConfig.Username != "user" || sys.DockerAuthConfig.Password != "pass:word" {
+		t.Errorf("Expected user and pass:word credentials but they were %v", sys.DockerAuthConfig)
+	}
+	if _, err := (Options{Credentials: ":password"}).systemContext(); err == nil {
+		t.Error("Expected an error for credentials without username")
+	}
+}
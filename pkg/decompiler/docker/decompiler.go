@@ -19,71 +19,159 @@
 
 import (
 	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
 	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
 	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
 	"regexp"
-	"sort"
 	"strings"
+	"time"
 )
 
-type OrderedHistory []image.HistoryResponseItem
-
-func (o OrderedHistory) Len() int {
-	return len(o)
+type DockerProvider struct {
+	// Platform is the platform the image must match, any platform is accepted if nil
+	Platform *v1.Platform
 }
 
-func (o OrderedHistory) Less(i, j int) bool {
-	return o[i].Created < o[j].Created
+func (p DockerProvider) Name() string {
+	return "docker"
 }
 
-func (o OrderedHistory) Swap(i, j int) {
-	o[i], o[j] = o[j], o[i]
+// Check pings the Docker daemon and returns its API version
+func (p DockerProvider) Check(ctx context.Context) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create the Docker client")
+	}
+	defer cli.Close()
+	ping, err := cli.Ping(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Docker daemon at %s, API version %s", cli.DaemonHost(), ping.APIVersion), nil
 }
 
-type DockerProvider struct{}
-
-func (p DockerProvider) Decompile(imageName string) (*parser.Node, error) {
-	ctx, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+func (p DockerProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
-		return nil, nil
+		return nil, errors.Wrap(err, "unable to create the Docker client")
 	}
-	history, err := ctx.ImageHistory(context.Background(), imageName)
+	defer cli.Close()
+	history, err := cli.ImageHistory(ctx, imageName)
 	if err != nil {
-		return nil, nil
+		return nil, errors.Wrapf(err, "unable to get the history of image %s", imageName)
 	}
 
 	root := &parser.Node{}
-	sort.Sort(OrderedHistory(history))
-	for _, hist := range history {
-		if hist.Comment != "" && strings.HasPrefix(strings.ToUpper(hist.Comment), utils.FROM_INSTRUCTION) &&
-			hist.Size != 0 {
-			err := decompilerutils.Line2Node(hist.Comment, root)
-			if err != nil {
+	entries, err := decompilerutils.History2Node(toHistory(history), root)
+	if err != nil {
+		return nil, err
+	}
+	parseTree(root)
+
+	digest := ""
+	var diffIDs []string
+	var names []string
+	platform := v1.Platform{}
+	if inspect, _, err := cli.ImageInspectWithRaw(ctx, imageName); err == nil {
+		digest = inspect.ID
+		if len(inspect.RepoDigests) > 0 {
+			digest = inspect.RepoDigests[0][strings.Index(inspect.RepoDigests[0], "@")+1:]
+		}
+		platform = v1.Platform{
+			OS:           inspect.Os,
+			Architecture: inspect.Architecture,
+			Variant:      inspect.Variant,
+		}
+		diffIDs = inspect.RootFS.Layers
+		names = inspect.RepoTags
+		if inspect.Config != nil {
+			if err := decompilerutils.Config2Node(toConfig(inspect.Config), root); err != nil {
 				return nil, err
 			}
 		}
-		if hist.CreatedBy != "" {
-			cmd := decompilerutils.ExtractCmd(hist.CreatedBy)
-			if cmd != "" {
-				err := decompilerutils.Line2Node(cmd, root)
-				if err != nil {
-					return nil, err
-				}
-			}
+	}
+	if err := decompilerutils.CheckPlatform(p.Platform, imageName, platform); err != nil {
+		return nil, err
+	}
+	return &decompilerutils.Image{
+		Node:          root,
+		Digest:        digest,
+		Platform:      platform.String(),
+		History:       entries,
+		LayerSizes:    layerSizes(history),
+		DiffIDs:       diffIDs,
+		HistoryLength: len(history),
+		Names:         names,
+	}, nil
+}
+
+// toHistory converts the history returned by the Docker daemon, the most recent entry first,
+// to the history of the image config
+func toHistory(dockerHistory []image.HistoryResponseItem) []v1.History {
+	history := make([]v1.History, len(dockerHistory))
+	for i, hist := range dockerHistory {
+		history[len(dockerHistory)-1-i] = v1.History{
+			CreatedBy:  hist.CreatedBy,
+			Comment:    hist.Comment,
+			EmptyLayer: hist.Size == 0,
+		}
+		if hist.Created > 0 {
+			history[len(dockerHistory)-1-i].Created = v1.Time{Time: time.Unix(hist.Created, 0)}
 		}
 	}
-	parseTree(root)
-	return root, nil
+	return history
+}
+
+// layerSizes returns the sizes of the layers created by the history returned by the Docker daemon
+func layerSizes(dockerHistory []image.HistoryResponseItem) []int64 {
+	var sizes []int64
+	for i := len(dockerHistory) - 1; i >= 0; i-- {
+		if dockerHistory[i].Size != 0 {
+			sizes = append(sizes, dockerHistory[i].Size)
+		}
+	}
+	return sizes
+}
+
+// toConfig converts the configuration of a Docker image
+func toConfig(dockerConfig *container.Config) v1.Config {
+	config := v1.Config{
+		ExposedPorts: map[string]struct{}{},
+		Env:          dockerConfig.Env,
+		Labels:       dockerConfig.Labels,
+		Volumes:      dockerConfig.Volumes,
+		WorkingDir:   dockerConfig.WorkingDir,
+		StopSignal:   dockerConfig.StopSignal,
+		User:         dockerConfig.User,
+		Entrypoint:   dockerConfig.Entrypoint,
+		Cmd:          dockerConfig.Cmd,
+	}
+	for port := range dockerConfig.ExposedPorts {
+		config.ExposedPorts[string(port)] = struct{}{}
+	}
+	if dockerConfig.Healthcheck != nil {
+		config.Healthcheck = &v1.HealthConfig{
+			Test:        dockerConfig.Healthcheck.Test,
+			Interval:    dockerConfig.Healthcheck.Interval,
+			Timeout:     dockerConfig.Healthcheck.Timeout,
+			StartPeriod: dockerConfig.Healthcheck.StartPeriod,
+			Retries:     dockerConfig.Healthcheck.Retries,
+		}
+	}
+	return config
 }
 
 var portExpr, _ = regexp.Compile("(?:map\\[)?(\\d+\\/(?:tcp|udp))\\:{}\\]?")
 
 func parseTree(node *parser.Node) {
 	for _, child := range node.Children {
-		if child.Value+" " == utils.EXPOSE_INSTRUCTION {
+		if strings.ToUpper(child.Value)+" " == utils.EXPOSE_INSTRUCTION {
 			next := child.Next
 			for next != nil {
 				ports := portExpr.FindStringSubmatch(next.Value)
@@ -92,6 +180,15 @@ func parseTree(node *parser.Node) {
 				}
 				next = next.Next
 			}
+			child.Original = strings.TrimSpace(utils.EXPOSE_INSTRUCTION + strings.Join(nodeValues(child.Next), " "))
 		}
 	}
 }
+
+func nodeValues(node *parser.Node) []string {
+	var values []string
+	for ; node != nil; node = node.Next {
+		values = append(values, node.Value)
+	}
+	return values
+}
@@ -0,0 +1,74 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"strings"
+)
+
+// Checker is implemented by the providers depending on the environment (a service, a configuration...)
+type Checker interface {
+	// Check returns details about the environment used by the provider or the reason why it is not usable
+	Check(ctx context.Context) (string, error)
+}
+
+// ProviderStatus reports whether a provider can be used to look up images
+type ProviderStatus struct {
+	Provider string `json:"provider"`
+	Usable   bool   `json:"usable"`
+	Details  string `json:"details"`
+}
+
+// Doctor checks the providers of the selected source, all of them if empty or auto
+func Doctor(ctx context.Context, options Options) ([]ProviderStatus, error) {
+	sources := []string{options.Source}
+	if options.Source == "" || options.Source == SourceAuto {
+		sources = Sources[1:]
+	}
+	statuses := []ProviderStatus{}
+	for _, source := range sources {
+		sourceOptions := options
+		sourceOptions.Source = source
+		providers, err := getProviders("", sourceOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, provider := range providers {
+			statuses = append(statuses, checkProvider(ctx, provider))
+		}
+	}
+	return statuses, nil
+}
+
+func checkProvider(ctx context.Context, provider Provider) ProviderStatus {
+	status := ProviderStatus{
+		Provider: provider.Name(),
+		Usable:   true,
+		Details:  "reads local files",
+	}
+	if checker, ok := provider.(Checker); ok {
+		details, err := checker.Check(ctx)
+		if err != nil {
+			status.Usable = false
+			details = err.Error()
+		}
+		status.Details = strings.TrimSpace(details)
+	}
+	return status
+}
@@ -0,0 +1,94 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorLocalFiles(t *testing.T) {
+	statuses, err := Doctor(context.Background(), Options{Source: SourceArchive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Expected the status of 2 providers but they were %d", len(statuses))
+	}
+	for _, status := range statuses {
+		if !status.Usable {
+			t.Errorf("Expected provider %s to be usable", status.Provider)
+		}
+	}
+}
+
+func TestDoctorAllProviders(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "registries.conf")
+	if err := os.WriteFile(conf, []byte("[[registry]]\nlocation = \"registry.example.com\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONTAINER_HOST", "unix://"+filepath.Join(dir, "podman.sock"))
+	t.Setenv("DOCKER_HOST", "unix://"+filepath.Join(dir, "docker.sock"))
+
+	statuses, err := Doctor(context.Background(), Options{RegistriesConf: conf, AuthFile: filepath.Join(dir, "auth.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		provider string
+		usable   bool
+		details  string
+	}{
+		{"podman", false, "unable to connect to the Podman service at unix://" + dir},
+		// the outcome depends on the storage of the host
+		{"containers-storage", false, ""},
+		{"docker", false, ""},
+		{"registry", true, conf + " parsed with 1 registries"},
+		{"oci-layout", true, "reads local files"},
+		{"docker-archive", true, "reads local files"},
+		{"oci-archive", true, "reads local files"},
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("Expected the status of %d providers but they were %v", len(expected), statuses)
+	}
+	for i, status := range statuses {
+		if status.Provider != expected[i].provider {
+			t.Errorf("Expected provider %s at %d but it was %s", expected[i].provider, i, status.Provider)
+			continue
+		}
+		if status.Details == "" {
+			t.Errorf("Expected details for provider %s", status.Provider)
+		}
+		if status.Provider == "containers-storage" {
+			continue
+		}
+		if status.Usable != expected[i].usable || !strings.HasPrefix(status.Details, expected[i].details) {
+			t.Errorf("Expected provider %s to be usable=%t with details %q but it was %t with %q", status.Provider, expected[i].usable, expected[i].details, status.Usable, status.Details)
+		}
+	}
+}
+
+func TestDoctorUnknownSource(t *testing.T) {
+	if _, err := Doctor(context.Background(), Options{Source: "unknown"}); err == nil {
+		t.Error("Expected an error for an unknown source")
+	}
+}
@@ -0,0 +1,58 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+)
+
+type LayoutProvider struct {
+	// Platform is the platform to select in a multi-architecture index, the default one is selected if nil
+	Platform *v1.Platform
+}
+
+func (p LayoutProvider) Name() string {
+	return "oci-layout"
+}
+
+// Decompile handles the oci:/path/to/layout[:tag] references, other references are ignored
+func (p LayoutProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	if !strings.HasPrefix(imageName, utils.OCI_LAYOUT_TRANSPORT) {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, tag := decompilerutils.SplitReference(strings.TrimPrefix(imageName, utils.OCI_LAYOUT_TRANSPORT))
+	img, platforms, err := decompilerutils.ImageFromLayout(path, tag, p.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := decompilerutils.ImageToNode(img)
+	if err != nil {
+		return nil, err
+	}
+	image.Platforms = platforms
+	return image, nil
+}
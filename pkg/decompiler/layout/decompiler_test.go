@@ -0,0 +1,112 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+)
+
+func writeLayout(t *testing.T, tags ...string) string {
+	p := testutil.NewLayout(t)
+	for _, tag := range tags {
+		testutil.AppendImage(t, p, tag, testutil.NewImage(t, &v1.ConfigFile{
+			Config: v1.Config{
+				User: "1001",
+			},
+			History: []v1.History{
+				{CreatedBy: "/bin/sh -c #(nop) EXPOSE 80/tcp", EmptyLayer: true},
+			},
+		}))
+	}
+	return string(p)
+}
+
+func TestDecompileLayout(t *testing.T) {
+	path := writeLayout(t, "1.0", "2.0")
+	for _, reference := range []string{"oci:" + path + ":1.0", "oci:" + path + ":2.0"} {
+		image, err := LayoutProvider{}.Decompile(context.Background(), reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(image.Node.Children) != 2 || !strings.EqualFold(image.Node.Children[0].Value, "expose") || !strings.EqualFold(image.Node.Children[1].Value, "user") {
+			t.Errorf("Expected EXPOSE and USER instructions but it was %s", image.Node.Dump())
+		}
+	}
+}
+
+func TestDecompileLayoutWithoutTag(t *testing.T) {
+	if _, err := (LayoutProvider{}).Decompile(context.Background(), "oci:"+writeLayout(t, "1.0")); err != nil {
+		t.Errorf("Expected the single manifest to be selected but it was %s", err)
+	}
+	if _, err := (LayoutProvider{}).Decompile(context.Background(), "oci:"+writeLayout(t, "1.0", "2.0")); err == nil {
+		t.Error("Expected an error when the tag is missing and the layout contains several manifests")
+	}
+}
+
+func TestDecompileLayoutIgnoresOtherReferences(t *testing.T) {
+	image, err := LayoutProvider{}.Decompile(context.Background(), "docker.io/nginx:1.25.3")
+	if image != nil || err != nil {
+		t.Errorf("Expected the reference to be ignored but it was %v, %v", image, err)
+	}
+}
+
+// writeIndexLayout writes a layout with a multi-architecture index tagged 1.0, the user of each image is its architecture
+func writeIndexLayout(t *testing.T, architectures ...string) string {
+	var images []v1.Image
+	for _, architecture := range architectures {
+		images = append(images, testutil.NewImage(t, &v1.ConfigFile{
+			Architecture: architecture,
+			Config: v1.Config{
+				User: architecture,
+			},
+		}))
+	}
+	p := testutil.NewLayout(t)
+	testutil.AppendIndex(t, p, "1.0", testutil.NewIndex(t, images...))
+	return string(p)
+}
+
+func TestDecompileLayoutPlatform(t *testing.T) {
+	path := writeIndexLayout(t, "amd64", "arm64")
+	image, err := LayoutProvider{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}.Decompile(context.Background(), "oci:"+path+":1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Platform != "linux/arm64" || image.Node.Children[0].Next.Value != "arm64" {
+		t.Errorf("Expected the linux/arm64 image but it was %s", image.Platform)
+	}
+	if strings.Join(image.Platforms, ",") != "linux/amd64,linux/arm64" {
+		t.Errorf("Expected the linux/amd64 and linux/arm64 platforms but they were %v", image.Platforms)
+	}
+
+	if _, err := (LayoutProvider{Platform: &v1.Platform{OS: "linux", Architecture: "s390x"}}).Decompile(context.Background(), "oci:"+path+":1.0"); err == nil {
+		t.Error("Expected an error for a platform missing in the index")
+	}
+}
+
+func TestDecompileLayoutPlatformMismatch(t *testing.T) {
+	path := writeLayout(t, "1.0")
+	if _, err := (LayoutProvider{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}).Decompile(context.Background(), "oci:"+path+":1.0"); err == nil {
+		t.Error("Expected an error for an image of another platform")
+	}
+}
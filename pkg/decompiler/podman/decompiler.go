@@ -19,81 +19,197 @@
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
 	"github.com/containers/common/pkg/config"
 	"github.com/containers/podman/v4/pkg/bindings"
 	"github.com/containers/podman/v4/pkg/bindings/images"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
-	"sort"
-	"strings"
+	"github.com/pkg/errors"
 
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
-	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
 )
 
-type OrderedHistory []v1.History
+type PodmanProvider struct {
+	// Connection is the name of the connection to use, the default one is resolved as the podman CLI does if empty
+	Connection string
+	// Platform is the platform the image must match, any platform is accepted if nil
+	Platform *ggcrv1.Platform
+}
 
-func (o OrderedHistory) Len() int {
-	return len(o)
+// getPodmanConnection resolves the URI and identity of the Podman service in the same order as the podman CLI:
+// the named connection, the CONTAINER_HOST and CONTAINER_CONNECTION environment variables,
+// the default connection of containers.conf and finally the default rootless or rootful socket
+func getPodmanConnection(connection string) (string, string, error) {
+	if connection != "" {
+		return getNamedConnection(connection)
+	}
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host, os.Getenv("CONTAINER_SSHKEY"), nil
+	}
+	if name := os.Getenv("CONTAINER_CONNECTION"); name != "" {
+		return getNamedConnection(name)
+	}
+	if conf, err := config.NewConfig(""); err == nil && conf.Engine.ActiveService != "" {
+		return getNamedConnection(conf.Engine.ActiveService)
+	}
+	return getDefaultSocket(), "", nil
 }
 
-func (o OrderedHistory) Less(i, j int) bool {
-	return o[i].Created.Before(*o[j].Created)
+func getNamedConnection(name string) (string, string, error) {
+	conf, err := config.NewConfig("")
+	if err != nil {
+		return "", "", err
+	}
+	destination, ok := conf.Engine.ServiceDestinations[name]
+	if !ok {
+		return "", "", errors.Errorf("unknown Podman connection %s", name)
+	}
+	return destination.URI, destination.Identity, nil
 }
 
-func (o OrderedHistory) Swap(i, j int) {
-	o[i], o[j] = o[j], o[i]
+// getDefaultSocket returns the rootless socket for non-root users or the rootful one, if it exists
+func getDefaultSocket() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	socket := "/run/podman/podman.sock"
+	if os.Getuid() != 0 {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+		}
+		socket = filepath.Join(runtimeDir, "podman", "podman.sock")
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return ""
+	}
+	return "unix://" + socket
 }
 
-type PodmanProvider struct{}
+func (p PodmanProvider) Name() string {
+	return "podman"
+}
 
-func getPodmanConnection() (string, string) {
-	conf, err := config.NewConfig("")
+// Check connects to the Podman service and returns its API version
+func (p PodmanProvider) Check(ctx context.Context) (string, error) {
+	uri, identity, err := getPodmanConnection(p.Connection)
 	if err != nil {
-		return "", ""
+		return "", err
 	}
-	uri := ""
-	identity := ""
-	if conf.Engine.ActiveService != "" {
-		uri = conf.Engine.ServiceDestinations[conf.Engine.ActiveService].URI
-		identity = conf.Engine.ServiceDestinations[conf.Engine.ActiveService].Identity
+	if uri == "" {
+		return "", errors.New("no Podman service found")
+	}
+	conn, err := bindings.NewConnectionWithIdentity(ctx, uri, identity, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to connect to the Podman service at %s", uri)
 	}
-	return uri, identity
+	return fmt.Sprintf("Podman service at %s, API version %s", uri, bindings.ServiceVersion(conn)), nil
 }
 
-func (p PodmanProvider) Decompile(imageName string) (*parser.Node, error) {
-	uri, identity := getPodmanConnection()
+func (p PodmanProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	uri, identity, err := getPodmanConnection(p.Connection)
+	if err != nil {
+		return nil, err
+	}
 	if uri != "" {
-		ctx, err := bindings.NewConnectionWithIdentity(context.Background(), uri, identity, false)
+		conn, err := bindings.NewConnectionWithIdentity(ctx, uri, identity, false)
 		if err != nil {
-			return nil, nil
+			return nil, errors.Wrapf(err, "unable to connect to the Podman service at %s", uri)
 		}
-		image, err := images.GetImage(ctx, imageName, nil)
+		image, err := images.GetImage(conn, imageName, nil)
 		if err != nil {
-			return nil, nil
+			return nil, errors.Wrapf(err, "unable to find image %s", imageName)
+		}
+		platform := ggcrv1.Platform{
+			OS:           image.Os,
+			Architecture: image.Architecture,
+		}
+		if err := decompilerutils.CheckPlatform(p.Platform, imageName, platform); err != nil {
+			return nil, err
 		}
 
 		root := &parser.Node{}
-		sort.Sort(OrderedHistory(image.History))
-		for _, hist := range image.History {
-			if hist.Comment != "" && strings.HasPrefix(strings.ToUpper(hist.Comment), utils.FROM_INSTRUCTION) &&
-				!hist.EmptyLayer {
-				err := decompilerutils.Line2Node(hist.Comment, root)
-				if err != nil {
-					return nil, err
+		history, err := decompilerutils.History2Node(toHistory(image.History), root)
+		if err != nil {
+			return nil, err
+		}
+		if image.Config != nil {
+			config := ggcrv1.Config{
+				ExposedPorts: image.Config.ExposedPorts,
+				Env:          image.Config.Env,
+				Labels:       image.Config.Labels,
+				Volumes:      image.Config.Volumes,
+				WorkingDir:   image.Config.WorkingDir,
+				StopSignal:   image.Config.StopSignal,
+				User:         image.Config.User,
+				Entrypoint:   image.Config.Entrypoint,
+				Cmd:          image.Config.Cmd,
+			}
+			if image.HealthCheck != nil {
+				config.Healthcheck = &ggcrv1.HealthConfig{
+					Test:        image.HealthCheck.Test,
+					Interval:    image.HealthCheck.Interval,
+					Timeout:     image.HealthCheck.Timeout,
+					StartPeriod: image.HealthCheck.StartPeriod,
+					Retries:     image.HealthCheck.Retries,
 				}
 			}
-			if hist.CreatedBy != "" {
-				cmd := decompilerutils.ExtractCmd(hist.CreatedBy)
-				if cmd != "" {
-					err := decompilerutils.Line2Node(cmd, root)
-					if err != nil {
-						return nil, err
-					}
+			if err := decompilerutils.Config2Node(config, root); err != nil {
+				return nil, err
+			}
+		}
+		var layerSizes []int64
+		if sizes, err := images.History(conn, imageName, nil); err == nil && len(sizes) == len(image.History) {
+			// the history is returned the most recent entry first
+			for i, hist := range image.History {
+				if !hist.EmptyLayer {
+					layerSizes = append(layerSizes, sizes[len(sizes)-1-i].Size)
 				}
 			}
 		}
-		return root, nil
+		digest := image.Digest.String()
+		if digest == "" {
+			digest = "sha256:" + image.ID
+		}
+		var diffIDs []string
+		if image.RootFS != nil {
+			for _, layer := range image.RootFS.Layers {
+				diffIDs = append(diffIDs, layer.String())
+			}
+		}
+		return &decompilerutils.Image{
+			Node:          root,
+			Digest:        digest,
+			Platform:      platform.String(),
+			History:       history,
+			LayerSizes:    layerSizes,
+			DiffIDs:       diffIDs,
+			HistoryLength: len(image.History),
+			Names:         image.RepoTags,
+		}, nil
+	}
+	return nil, errors.New("no Podman service found")
+}
+
+// toHistory converts the history of an OCI image
+func toHistory(ociHistory []v1.History) []ggcrv1.History {
+	history := make([]ggcrv1.History, len(ociHistory))
+	for i, hist := range ociHistory {
+		history[i] = ggcrv1.History{
+			Author:     hist.Author,
+			CreatedBy:  hist.CreatedBy,
+			Comment:    hist.Comment,
+			EmptyLayer: hist.EmptyLayer,
+		}
+		if hist.Created != nil {
+			history[i].Created = ggcrv1.Time{Time: *hist.Created}
+		}
 	}
-	return nil, nil
+	return history
 }
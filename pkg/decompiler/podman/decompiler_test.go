@@ -0,0 +1,74 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setContainersConf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "containers.conf")
+	content := `[engine]
+active_service = "dev"
+
+[engine.service_destinations.dev]
+uri = "ssh://core@localhost:2222/run/user/1000/podman/podman.sock"
+identity = "/home/user/.ssh/dev"
+
+[engine.service_destinations.prod]
+uri = "ssh://core@prod:22/run/podman/podman.sock"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONTAINERS_CONF", path)
+	t.Setenv("CONTAINER_HOST", "")
+	t.Setenv("CONTAINER_CONNECTION", "")
+}
+
+func TestPodmanConnectionResolutionOrder(t *testing.T) {
+	setContainersConf(t)
+	verifyPodmanConnection(t, "", "ssh://core@localhost:2222/run/user/1000/podman/podman.sock", "/home/user/.ssh/dev")
+
+	t.Setenv("CONTAINER_CONNECTION", "prod")
+	verifyPodmanConnection(t, "", "ssh://core@prod:22/run/podman/podman.sock", "")
+
+	t.Setenv("CONTAINER_HOST", "unix:///tmp/podman.sock")
+	verifyPodmanConnection(t, "", "unix:///tmp/podman.sock", "")
+
+	verifyPodmanConnection(t, "dev", "ssh://core@localhost:2222/run/user/1000/podman/podman.sock", "/home/user/.ssh/dev")
+}
+
+func TestUnknownPodmanConnection(t *testing.T) {
+	setContainersConf(t)
+	if _, _, err := getPodmanConnection("unknown"); err == nil {
+		t.Error("Expected an error for an unknown connection")
+	}
+}
+
+func verifyPodmanConnection(t *testing.T, connection string, expectedURI string, expectedIdentity string) {
+	uri, identity, err := getPodmanConnection(connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != expectedURI || identity != expectedIdentity {
+		t.Errorf("Expected %s with identity %s but it was %s with identity %s", expectedURI, expectedIdentity, uri, identity)
+	}
+}
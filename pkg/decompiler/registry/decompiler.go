@@ -18,79 +18,223 @@
  package decompiler
 
 import (
-	"sort"
+	"context"
+	"fmt"
 	"strings"
 
-	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/containers/image/v5/docker/reference"
+	dockerconfig "github.com/containers/image/v5/pkg/docker/config"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/cache"
 	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
-	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
 )
 
-type OrderedHistory []v1.History
-
-func (o OrderedHistory) Len() int {
-	return len(o)
+// RegistryProvider fetches the images from their registry, resolving the short names, aliases,
+// mirrors and blocked registries from registries.conf as podman and buildah do
+type RegistryProvider struct {
+	// SystemContext overrides the location of registries.conf, the registry credentials and certificates,
+	// the default ones are used if nil
+	SystemContext *types.SystemContext
+	// Platform is the platform to select in a multi-architecture index, the default one is selected if nil
+	Platform *v1.Platform
+	// Cache stores the decompiled images and the digests the references were resolved to, nothing is cached if nil
+	Cache *cache.Cache
+	// Offline restricts the images to the cached ones, no registry is contacted
+	Offline bool
 }
 
-func (o OrderedHistory) Less(i, j int) bool {
-	return o[i].Created.Before(o[j].Created.Time)
-}
+// errOffline is returned for the images which are not cached in offline mode
+var errOffline = errors.New("the image is not cached and the registries are not contacted in offline mode")
 
-func (o OrderedHistory) Swap(i, j int) {
-	o[i], o[j] = o[j], o[i]
+// pullSource is a reference to try to fetch an image, in the registry or one of its mirrors
+type pullSource struct {
+	Reference string
+	// Host is the host[:port] of the registry
+	Host string
+	// Blocked is set if the registry of the reference is blocked in registries.conf
+	Blocked bool
+	// Insecure is set if the registry can be reached with plain HTTP or without verifying its certificate
+	Insecure bool
 }
 
-type RegistryProvider struct{}
+func (p RegistryProvider) Name() string {
+	return "registry"
+}
 
-func (p RegistryProvider) Decompile(imageName string) (*parser.Node, error) {
-	ref, err := name.ParseReference(imageName)
+// Check parses registries.conf and counts the registries with credentials, no registry is contacted
+func (p RegistryProvider) Check(ctx context.Context) (string, error) {
+	registries, err := sysregistriesv2.GetRegistries(p.SystemContext)
 	if err != nil {
-		return nil, err
+		return "", errors.Wrapf(err, "unable to parse %s", sysregistriesv2.ConfigPath(p.SystemContext))
 	}
+	details := fmt.Sprintf("%s parsed with %d registries", sysregistriesv2.ConfigPath(p.SystemContext), len(registries))
 
-	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	credentials, err := dockerconfig.GetAllCredentials(p.SystemContext)
 	if err != nil {
-		return nil, nil
+		return "", errors.Wrap(err, "unable to read the registry credentials")
 	}
+	return details + fmt.Sprintf(", credentials found for %d registries", len(credentials)), nil
+}
 
-	configFile, err := img.ConfigFile()
+func (p RegistryProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	sources, err := p.pullSources(imageName)
 	if err != nil {
 		return nil, err
 	}
 
-	root := &parser.Node{}
-
-	history := configFile.History
-	sort.Sort(OrderedHistory(history))
-	for _, hist := range history {
-		if hist.Comment != "" && strings.HasPrefix(strings.ToUpper(hist.Comment), utils.FROM_INSTRUCTION) &&
-			!hist.EmptyLayer {
-			err := decompilerutils.Line2Node(hist.Comment, root)
-			if err != nil {
-				return nil, err
+	var pullErrors []string
+	for _, source := range sources {
+		if source.Blocked {
+			pullErrors = append(pullErrors, fmt.Sprintf("%s: the registry is blocked", source.Reference))
+			continue
+		}
+		insecure := source.Insecure || (p.SystemContext != nil && p.SystemContext.DockerInsecureSkipTLSVerify == types.OptionalBoolTrue)
+		var nameOptions []name.Option
+		if insecure {
+			nameOptions = append(nameOptions, name.Insecure)
+		}
+		ref, err := name.ParseReference(source.Reference, nameOptions...)
+		if err != nil {
+			return nil, err
+		}
+		transport, err := p.transport(source.Host, insecure)
+		if err != nil {
+			return nil, err
+		}
+		image, err := p.fetch(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(p.keychain()), remote.WithTransport(transport))
+		if err != nil {
+			if _, ok := err.(pullError); ok {
+				pullErrors = append(pullErrors, fmt.Sprintf("%s: %s", source.Reference, err))
+				continue
 			}
+			return nil, err
 		}
-		if hist.CreatedBy != "" {
-			cmd := decompilerutils.ExtractCmd(hist.CreatedBy)
-			if cmd != "" {
-				err := decompilerutils.Line2Node(cmd, root)
-				if err != nil {
-					return nil, err
-				}
+		image.Reference = source.Reference
+		return image, nil
+	}
+	return nil, errors.Errorf("unable to fetch image %s: %s", imageName, strings.Join(pullErrors, "; "))
+}
+
+// pullError is an error reaching the registry, the next pull source is tried
+type pullError struct {
+	error
+}
+
+// fetch decompiles the image of a reference, from the cache if the reference was resolved recently or
+// its manifest digest is cached. The manifest digest is read with a HEAD request, which registries such as
+// docker.io don't count in their rate limits
+func (p RegistryProvider) fetch(ref name.Reference, options ...remote.Option) (*decompilerutils.Image, error) {
+	key := p.resolutionKey(ref)
+	if image := p.Cache.Image(p.Cache.Resolution(key)); image != nil {
+		return image, nil
+	}
+	if p.Offline {
+		return nil, pullError{errOffline}
+	}
+	_, pinned := ref.(name.Digest)
+	if p.Cache != nil {
+		if head, err := remote.Head(ref, options...); err == nil {
+			if image := p.Cache.Image(p.Cache.Resolution(p.resolutionKey(ref.Context().Digest(head.Digest.String())))); image != nil {
+				_ = p.Cache.StoreResolution(key, image.Digest, pinned)
+				return image, nil
 			}
 		}
 	}
 
-	if configFile.Config.User != "" {
-		err := decompilerutils.Line2Node(utils.USER_INSTRUCTION+configFile.Config.User, root)
+	descriptor, err := remote.Get(ref, options...)
+	if err != nil {
+		return nil, pullError{err}
+	}
+	image, err := p.decompileDescriptor(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	// the cache is best effort, the image is decompiled again if it can't be stored
+	if err := p.Cache.StoreImage(image); err == nil {
+		_ = p.Cache.StoreResolution(key, image.Digest, pinned)
+		_ = p.Cache.StoreResolution(p.resolutionKey(ref.Context().Digest(descriptor.Digest.String())), image.Digest, true)
+	}
+	return image, nil
+}
+
+// resolutionKey identifies the image selected for a reference, which depends on the requested platform for an index
+func (p RegistryProvider) resolutionKey(ref name.Reference) string {
+	platform := ""
+	if p.Platform != nil {
+		platform = p.Platform.String()
+	}
+	return ref.Name() + " " + platform
+}
+
+// decompileDescriptor decompiles the image of a manifest or, for a multi-architecture index, of the selected platform
+func (p RegistryProvider) decompileDescriptor(descriptor *remote.Descriptor) (*decompilerutils.Image, error) {
+	if !descriptor.MediaType.IsIndex() {
+		img, err := descriptor.Image()
+		if err != nil {
+			return nil, err
+		}
+		configFile, err := img.ConfigFile()
 		if err != nil {
 			return nil, err
 		}
+		if err := decompilerutils.CheckPlatform(p.Platform, descriptor.Ref.String(), decompilerutils.ConfigPlatform(configFile)); err != nil {
+			return nil, err
+		}
+		return decompilerutils.ImageToNode(img)
 	}
+	index, err := descriptor.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	img, platforms, err := decompilerutils.SelectPlatformImage(index, p.Platform)
+	if err != nil {
+		return nil, err
+	}
+	image, err := decompilerutils.ImageToNode(img)
+	if err != nil {
+		return nil, err
+	}
+	image.Platforms = platforms
+	return image, nil
+}
 
-	return root, nil
+// pullSources resolves the image name to the references to try in order: the alias or the unqualified-search
+// registries of a short name and, for each of them, the mirrors of its registry before the registry itself
+func (p RegistryProvider) pullSources(imageName string) ([]pullSource, error) {
+	candidates, err := decompilerutils.ResolveShortName(p.SystemContext, imageName)
+	if err != nil {
+		return nil, err
+	}
+	var sources []pullSource
+	for _, candidate := range candidates {
+		registry, err := sysregistriesv2.FindRegistry(p.SystemContext, candidate.String())
+		if err != nil {
+			return nil, err
+		}
+		if registry == nil {
+			sources = append(sources, pullSource{Reference: candidate.String(), Host: reference.Domain(candidate)})
+			continue
+		}
+		if registry.Blocked {
+			sources = append(sources, pullSource{Reference: candidate.String(), Host: reference.Domain(candidate), Blocked: true})
+			continue
+		}
+		registrySources, err := registry.PullSourcesFromReference(candidate)
+		if err != nil {
+			return nil, err
+		}
+		for _, registrySource := range registrySources {
+			sources = append(sources, pullSource{
+				Reference: registrySource.Reference.String(),
+				Host:      reference.Domain(registrySource.Reference),
+				Insecure:  registrySource.Endpoint.Insecure,
+			})
+		}
+	}
+	return sources, nil
 }
@@ -0,0 +1,215 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/cache"
+)
+
+const testRegistriesConf = `
+unqualified-search-registries = ["registry.example.com", "docker.io"]
+
+[aliases]
+"ubi9" = "registry.access.redhat.com/ubi9"
+
+[[registry]]
+location = "docker.io"
+
+[[registry.mirror]]
+location = "mirror.example.com"
+
+[[registry]]
+location = "blocked.example.com"
+blocked = true
+`
+
+func newTestProvider(t *testing.T, conf string) RegistryProvider {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registries.conf")
+	if err := os.WriteFile(path, []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return RegistryProvider{
+		SystemContext: &types.SystemContext{
+			SystemRegistriesConfPath:    path,
+			SystemRegistriesConfDirPath: filepath.Join(dir, "registries.conf.d"),
+			UserShortNameAliasConfPath:  filepath.Join(dir, "shortnames.conf"),
+		},
+	}
+}
+
+func TestPullSources(t *testing.T) {
+	provider := newTestProvider(t, testRegistriesConf)
+	expected := map[string][]string{
+		"ubi9":                         {"registry.access.redhat.com/ubi9:latest"},
+		"nginx:1.25":                   {"registry.example.com/nginx:1.25", "mirror.example.com/library/nginx:1.25", "docker.io/library/nginx:1.25"},
+		"quay.io/podman/stable:latest": {"quay.io/podman/stable:latest"},
+	}
+	for imageName, references := range expected {
+		sources, err := provider.pullSources(imageName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sources) != len(references) {
+			t.Fatalf("Expected %d sources for %s but they were %v", len(references), imageName, sources)
+		}
+		for i, source := range sources {
+			if source.Reference != references[i] || source.Blocked {
+				t.Errorf("Expected source %s for %s but it was %v", references[i], imageName, source)
+			}
+		}
+	}
+}
+
+func TestBlockedRegistry(t *testing.T) {
+	provider := newTestProvider(t, testRegistriesConf)
+	_, err := provider.Decompile(context.Background(), "blocked.example.com/app:1.0")
+	if err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("Expected an error for a blocked registry but it was %v", err)
+	}
+}
+
+func TestShortNameWithoutSearchRegistries(t *testing.T) {
+	provider := newTestProvider(t, "")
+	sources, err := provider.pullSources("nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].Reference != "docker.io/library/nginx:latest" {
+		t.Errorf("Expected short names to fall back to docker.io but they were %v", sources)
+	}
+}
+
+// newCountingRegistry starts a registry with a self-signed certificate holding the image app:1.0 and counts
+// the manifests fetched once it is written
+func newCountingRegistry(t *testing.T) (*httptest.Server, string, *int) {
+	manifestGets := 0
+	handler := registry.New()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/manifests/") {
+			manifestGets++
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	imageName := strings.TrimPrefix(server.URL, "https://") + "/app:1.0"
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img, remote.WithTransport(server.Client().Transport)); err != nil {
+		t.Fatal(err)
+	}
+	manifestGets = 0
+	return server, imageName, &manifestGets
+}
+
+// newCachingProvider returns a provider skipping the verification of certificates with an empty cache
+func newCachingProvider(t *testing.T) RegistryProvider {
+	provider := newTestProvider(t, "")
+	provider.SystemContext.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	provider.Cache = &cache.Cache{Dir: t.TempDir(), TTL: time.Hour}
+	return provider
+}
+
+func TestCachedImage(t *testing.T) {
+	_, imageName, manifestGets := newCountingRegistry(t)
+	provider := newCachingProvider(t)
+	first, err := provider.Decompile(context.Background(), imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := provider.Decompile(context.Background(), imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *manifestGets != 1 {
+		t.Errorf("Expected the manifest to be fetched once but it was fetched %d times", *manifestGets)
+	}
+	if second.Digest != first.Digest || len(second.Node.Children) != len(first.Node.Children) || second.Reference != imageName {
+		t.Errorf("Expected the cached image %s but it was %s", first.Digest, second.Digest)
+	}
+
+	// once the resolution of the tag expired, the digest is read with a HEAD request and the image is still cached
+	provider.Cache.TTL = -time.Second
+	if _, err := provider.Decompile(context.Background(), imageName); err != nil {
+		t.Fatal(err)
+	}
+	if *manifestGets != 1 {
+		t.Errorf("Expected the image of the unchanged digest to be cached but the manifest was fetched %d times", *manifestGets)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	server, imageName, manifestGets := newCountingRegistry(t)
+	provider := newCachingProvider(t)
+	provider.Offline = true
+	if _, err := provider.Decompile(context.Background(), imageName); err == nil || !strings.Contains(err.Error(), "offline mode") {
+		t.Errorf("Expected an offline error for an image which is not cached but it was %v", err)
+	}
+	if *manifestGets != 0 {
+		t.Errorf("Expected no manifest to be fetched in offline mode but %d were", *manifestGets)
+	}
+
+	provider.Offline = false
+	if _, err := provider.Decompile(context.Background(), imageName); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	provider.Offline = true
+	if _, err := provider.Decompile(context.Background(), imageName); err != nil {
+		t.Errorf("Expected the cached image to be found offline but it was %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	provider := newTestProvider(t, "")
+	provider.SystemContext.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := provider.Decompile(ctx, strings.TrimPrefix(server.URL, "https://")+"/app:1.0"); err == nil {
+		t.Error("Expected an error once the deadline is reached")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the registry requests to stop at the deadline but they took %s", elapsed)
+	}
+}
@@ -0,0 +1,51 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	dockerconfig "github.com/containers/image/v5/pkg/docker/config"
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// containersKeychain resolves the registry credentials as podman does: the --creds credentials, the auth file
+// set with --authfile or REGISTRY_AUTH_FILE, $XDG_RUNTIME_DIR/containers/auth.json, $HOME/.config/containers/auth.json,
+// the Docker config files and the credential helpers of registries.conf
+type containersKeychain struct {
+	sys *types.SystemContext
+}
+
+func (k containersKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	auth, err := dockerconfig.GetCredentials(k.sys, target.String())
+	if err != nil {
+		return nil, err
+	}
+	if auth == (types.DockerAuthConfig{}) {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		IdentityToken: auth.IdentityToken,
+	}), nil
+}
+
+// keychain returns the containers credentials, falling back to the Docker credential helpers
+func (p RegistryProvider) keychain() authn.Keychain {
+	return authn.NewMultiKeychain(containersKeychain{sys: p.SystemContext}, authn.DefaultKeychain)
+}
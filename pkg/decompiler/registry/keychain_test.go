@@ -0,0 +1,77 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func resolveAuth(t *testing.T, sys *types.SystemContext, repository string) *authn.AuthConfig {
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := containersKeychain{sys: sys}.Resolve(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := authenticator.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestAuthFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	content := fmt.Sprintf(`{"auths": {"registry.example.com": {"auth": "%s"}}}`, base64.StdEncoding.EncodeToString([]byte("user:secret")))
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sys := &types.SystemContext{
+		AuthFilePath: path,
+	}
+
+	auth := resolveAuth(t, sys, "registry.example.com/app")
+	if auth.Username != "user" || auth.Password != "secret" {
+		t.Errorf("Expected the credentials of the auth file but they were %s", auth.Username)
+	}
+	if auth := resolveAuth(t, sys, "quay.io/app"); auth.Username != "" {
+		t.Errorf("Expected no credentials for another registry but they were %s", auth.Username)
+	}
+}
+
+func TestCreds(t *testing.T) {
+	sys := &types.SystemContext{
+		DockerAuthConfig: &types.DockerAuthConfig{
+			Username: "user",
+			Password: "secret",
+		},
+	}
+	if auth := resolveAuth(t, sys, "quay.io/app"); auth.Username != "user" || auth.Password != "secret" {
+		t.Errorf("Expected the credentials set with --creds but they were %s", auth.Username)
+	}
+}
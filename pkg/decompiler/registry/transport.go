@@ -0,0 +1,67 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/containers/image/v5/pkg/tlsclientconfig"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+// certsDirs are the directories holding the certificates of each registry, in the order podman looks them up
+func certsDirs() []string {
+	dirs := []string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "containers", "certs.d"))
+	}
+	return append(dirs, "/etc/containers/certs.d", "/etc/docker/certs.d")
+}
+
+// certDir returns the directory set with --cert-dir or the certs.d directory of the registry host[:port]
+func (p RegistryProvider) certDir(host string) string {
+	if p.SystemContext != nil && p.SystemContext.DockerCertPath != "" {
+		return p.SystemContext.DockerCertPath
+	}
+	for _, dir := range certsDirs() {
+		if _, err := os.Stat(filepath.Join(dir, host)); err == nil {
+			return filepath.Join(dir, host)
+		}
+	}
+	return ""
+}
+
+// transport returns the transport to reach a registry, trusting the CA certificates (*.crt) and presenting
+// the client certificates (*.cert and *.key) of its certificates directory
+func (p RegistryProvider) transport(host string, insecure bool) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+	if dir := p.certDir(host); dir != "" {
+		if err := tlsclientconfig.SetupCertificates(dir, tlsConfig); err != nil {
+			return nil, errors.Wrapf(err, "unable to load the certificates of %s", host)
+		}
+	}
+	transport := remote.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,101 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// newTLSRegistry starts a registry with a self-signed certificate holding the image app:1.0
+func newTLSRegistry(t *testing.T) (*httptest.Server, string) {
+	server := httptest.NewTLSServer(registry.New())
+	t.Cleanup(server.Close)
+	imageName := strings.TrimPrefix(server.URL, "https://") + "/app:1.0"
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img, remote.WithTransport(server.Client().Transport)); err != nil {
+		t.Fatal(err)
+	}
+	return server, imageName
+}
+
+func TestCertDir(t *testing.T) {
+	server, imageName := newTLSRegistry(t)
+	dir := t.TempDir()
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), certificate, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := newTestProvider(t, "")
+	if _, err := provider.Decompile(context.Background(), imageName); err == nil {
+		t.Error("Expected an error for an unknown certificate authority")
+	}
+	provider.SystemContext.DockerCertPath = dir
+	image, err := provider.Decompile(context.Background(), imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Reference != imageName {
+		t.Errorf("Expected image fetched from %s but it was %s", imageName, image.Reference)
+	}
+}
+
+func TestSkipTLSVerify(t *testing.T) {
+	_, imageName := newTLSRegistry(t)
+	provider := newTestProvider(t, "")
+	provider.SystemContext.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	if _, err := provider.Decompile(context.Background(), imageName); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInsecureRegistry(t *testing.T) {
+	_, imageName := newTLSRegistry(t)
+	host := imageName[:strings.Index(imageName, "/")]
+	provider := newTestProvider(t, "[[registry]]\nlocation = \""+host+"\"\ninsecure = true\n")
+	sources, err := provider.pullSources(imageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || !sources[0].Insecure || sources[0].Host != host {
+		t.Fatalf("Expected an insecure source for %s but they were %v", host, sources)
+	}
+	if _, err := provider.Decompile(context.Background(), imageName); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,302 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/types"
+	storagetypes "github.com/containers/storage/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	decompilerutils "github.com/redhat-developer/docker-openshift-analyzer/pkg/decompiler/utils"
+)
+
+// idExpr matches the image IDs, full or truncated
+var idExpr = regexp.MustCompile(`^(?:sha256:)?([0-9a-f]{12,64})$`)
+
+// manifestBigDataKey is the big data key of the manifest of the images
+const manifestBigDataKey = "manifest"
+
+// storageImage is the subset of the containers-storage image record read from images.json
+type storageImage struct {
+	ID           string          `json:"id"`
+	Digest       digest.Digest   `json:"digest,omitempty"`
+	Digests      []digest.Digest `json:"digests,omitempty"`
+	Names        []string        `json:"names,omitempty"`
+	BigDataNames []string        `json:"big-data-names,omitempty"`
+	// dir is the image store holding the image
+	dir string
+}
+
+// StorageProvider reads the images directly from the local containers-storage (as configured in storage.conf),
+// it does not need a Podman service. The metadata files of the image stores are read without locking the storage
+// or initializing its driver, so that it also works for rootless users outside of their user namespace
+type StorageProvider struct {
+	// SystemContext overrides the location of registries.conf used to resolve short names, the default one is used if nil
+	SystemContext *types.SystemContext
+	// StoreOptions are the options of the store, they are read from storage.conf if nil
+	StoreOptions *storagetypes.StoreOptions
+	// Platform is the platform the image must match, any platform is accepted if nil
+	Platform *v1.Platform
+}
+
+func (p StorageProvider) Name() string {
+	return "containers-storage"
+}
+
+// Check returns the location of the storage and the number of images it contains
+func (p StorageProvider) Check(ctx context.Context) (string, error) {
+	images, root, err := p.readImages()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d images found in %s", len(images), root), nil
+}
+
+func (p StorageProvider) Decompile(ctx context.Context, imageName string) (*decompilerutils.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	images, root, err := p.readImages()
+	if err != nil {
+		return nil, err
+	}
+	image, err := p.lookupImage(images, imageName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find image %s in %s", imageName, root)
+	}
+
+	content, err := readConfig(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read the config of image %s", imageName)
+	}
+	configFile, err := v1.ParseConfigFile(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	platform := decompilerutils.ConfigPlatform(configFile)
+	if err := decompilerutils.CheckPlatform(p.Platform, imageName, platform); err != nil {
+		return nil, err
+	}
+
+	decompiled, err := decompilerutils.ConfigFile2Image(configFile)
+	if err != nil {
+		return nil, err
+	}
+	decompiled.Digest = image.Digest.String()
+	if decompiled.Digest == "" {
+		decompiled.Digest = "sha256:" + image.ID
+	}
+	decompiled.Platform = platform.String()
+	decompiled.LayerSizes = readLayerSizes(image)
+	decompiled.Names = image.Names
+	return decompiled, nil
+}
+
+// readImages returns the images of the image stores of the storage and its graph root. A missing storage is an error,
+// a storage without image store has no image
+func (p StorageProvider) readImages() ([]storageImage, string, error) {
+	options := p.StoreOptions
+	if options == nil {
+		defaults, err := storagetypes.DefaultStoreOptionsAutoDetectUID()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "unable to read the storage configuration")
+		}
+		options = &defaults
+	}
+	if options.GraphRoot == "" {
+		return nil, "", errors.New("no storage configured")
+	}
+	if _, err := os.Stat(options.GraphRoot); err != nil {
+		return nil, "", errors.Wrapf(err, "no storage found at %s", options.GraphRoot)
+	}
+
+	var images []storageImage
+	for _, dir := range imagesDirs(*options) {
+		content, err := os.ReadFile(filepath.Join(dir, "images.json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		var dirImages []storageImage
+		if err := json.Unmarshal(content, &dirImages); err != nil {
+			return nil, "", errors.Wrapf(err, "unable to parse the images of %s", dir)
+		}
+		for i := range dirImages {
+			dirImages[i].dir = dir
+		}
+		images = append(images, dirImages...)
+	}
+	return images, options.GraphRoot, nil
+}
+
+// imagesDirs returns the image stores of the configured driver or of any driver if not set
+func imagesDirs(options storagetypes.StoreOptions) []string {
+	if options.GraphDriverName != "" {
+		return []string{filepath.Join(options.GraphRoot, options.GraphDriverName+"-images")}
+	}
+	dirs, _ := filepath.Glob(filepath.Join(options.GraphRoot, "*-images"))
+	return dirs
+}
+
+// lookupImage looks up an image by ID (or unique ID prefix), then by name[:tag] or name@digest as podman does:
+// short names are looked up in localhost first and then resolved with the aliases and unqualified-search
+// registries of registries.conf
+func (p StorageProvider) lookupImage(images []storageImage, imageName string) (*storageImage, error) {
+	if match := idExpr.FindStringSubmatch(imageName); match != nil {
+		var found *storageImage
+		for i := range images {
+			if strings.HasPrefix(images[i].ID, match[1]) {
+				if found != nil && found.ID != images[i].ID {
+					return nil, errors.Errorf("the ID %s matches several images", imageName)
+				}
+				found = &images[i]
+			}
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+	name, imageDigest, digested := strings.Cut(imageName, "@")
+	var candidates []reference.Named
+	if isShortName(name) {
+		if named, err := reference.ParseNormalizedNamed("localhost/" + name); err == nil {
+			candidates = append(candidates, named)
+		}
+	}
+	resolved, err := decompilerutils.ResolveShortName(p.SystemContext, name)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, resolved...)
+
+	for _, candidate := range candidates {
+		for i := range images {
+			if digested {
+				if images[i].hasDigest(digest.Digest(imageDigest)) && images[i].hasRepository(candidate.Name()) {
+					return &images[i], nil
+				}
+			} else if images[i].hasName(reference.TagNameOnly(candidate).String()) {
+				return &images[i], nil
+			}
+		}
+	}
+	return nil, errors.New("image not known")
+}
+
+// isShortName returns true if the name has no registry, e.g. app:1.0 and not quay.io/app:1.0 or localhost/app
+func isShortName(name string) bool {
+	i := strings.IndexRune(name, '/')
+	return i == -1 || (!strings.ContainsAny(name[:i], ".:") && name[:i] != "localhost")
+}
+
+func (image *storageImage) hasName(name string) bool {
+	for _, imageName := range image.Names {
+		if imageName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (image *storageImage) hasDigest(imageDigest digest.Digest) bool {
+	if image.Digest == imageDigest {
+		return true
+	}
+	for _, d := range image.Digests {
+		if d == imageDigest {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRepository returns true if the image is named in the repository
+func (image *storageImage) hasRepository(repository string) bool {
+	for _, name := range image.Names {
+		if named, err := reference.ParseNormalizedNamed(name); err == nil && named.Name() == repository {
+			return true
+		}
+	}
+	return false
+}
+
+// readBigData returns the big data item of the image stored under the key
+func (image *storageImage) readBigData(key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(image.dir, image.ID, bigDataBaseName(key)))
+}
+
+// readConfig returns the config of the image, stored as big data under the config digest
+func readConfig(image *storageImage) ([]byte, error) {
+	key := "sha256:" + image.ID
+	for _, name := range image.BigDataNames {
+		if name == key {
+			return image.readBigData(key)
+		}
+	}
+	// the ID is not the config digest, look for it in the manifest
+	manifest, err := image.readBigData(manifestBigDataKey)
+	if err != nil {
+		return nil, err
+	}
+	var m v1.Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil, err
+	}
+	return image.readBigData(m.Config.Digest.String())
+}
+
+// readLayerSizes returns the sizes of the layers of the image listed in its manifest, if any
+func readLayerSizes(image *storageImage) []int64 {
+	content, err := image.readBigData(manifestBigDataKey)
+	if err != nil {
+		return nil
+	}
+	var m v1.Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil
+	}
+	var sizes []int64
+	for _, layer := range m.Layers {
+		sizes = append(sizes, layer.Size)
+	}
+	return sizes
+}
+
+// bigDataBaseName returns the name of the file storing a big data item, as containers-storage does
+func bigDataBaseName(key string) string {
+	for _, ch := range key {
+		if ch != '.' && !(ch >= '0' && ch <= '9') && !(ch >= 'a' && ch <= 'z') {
+			return "=" + base64.StdEncoding.EncodeToString([]byte(key))
+		}
+	}
+	return key
+}
@@ -0,0 +1,140 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package decompiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/storage"
+	storagetypes "github.com/containers/storage/types"
+)
+
+const testImageID = "0b8e8a0b7f1c1b43c6e37c43b47e0d0a1a6d5e1f2a3b4c5d6e7f8091a2b3c4d5"
+
+const testManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+	`"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:` + testImageID + `","size":2},` +
+	`"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":"sha256:` + testImageID + `","size":42}]}`
+
+// newTestProvider returns a provider reading a store of the driver holding nginx:1.25.3, also named localhost/app:latest,
+// and resolving short names with docker.io only
+func newTestProvider(t *testing.T, driver string) (StorageProvider, string) {
+	dir := t.TempDir()
+	options := storagetypes.StoreOptions{
+		GraphRoot:       filepath.Join(dir, "root"),
+		RunRoot:         filepath.Join(dir, "run"),
+		GraphDriverName: driver,
+	}
+	store, err := storage.GetStore(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = store.Shutdown(true)
+	})
+	if _, err := store.CreateImage(testImageID, []string{"docker.io/library/nginx:1.25.3", "localhost/app:latest"}, "", "", &storage.ImageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"architecture":"amd64","os":"linux","config":{"User":"101"},"history":[{"created_by":"/bin/sh -c #(nop)  EXPOSE 80"}]}`
+	if err := store.SetImageBigData(testImageID, "sha256:"+testImageID, []byte(config), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetImageBigData(testImageID, storage.ImageDigestBigDataKey, []byte(testManifest), manifest.Digest); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := manifest.Digest([]byte(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := filepath.Join(dir, "registries.conf")
+	if err := os.WriteFile(conf, []byte(`unqualified-search-registries = ["docker.io"]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return StorageProvider{
+		SystemContext: &types.SystemContext{
+			SystemRegistriesConfPath:    conf,
+			SystemRegistriesConfDirPath: filepath.Join(dir, "registries.conf.d"),
+			UserShortNameAliasConfPath:  filepath.Join(dir, "shortnames.conf"),
+		},
+		StoreOptions: &options,
+	}, digest.String()
+}
+
+func TestLookupImageInStorage(t *testing.T) {
+	provider, digest := newTestProvider(t, "vfs")
+	images, _, err := provider.readImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, imageName := range []string{"nginx:1.25.3", "docker.io/nginx:1.25.3", "app", "localhost/app:latest", testImageID,
+		"sha256:" + testImageID[:12], "nginx@" + digest, "docker.io/library/nginx@" + digest, "app@" + digest} {
+		if image, err := provider.lookupImage(images, imageName); err != nil || image.ID != testImageID {
+			t.Errorf("Expected %s to be found but it was %v", imageName, err)
+		}
+	}
+	for _, imageName := range []string{"nginx", "quay.io/app:latest", "0b8e", "quay.io/nginx@" + digest} {
+		if image, err := provider.lookupImage(images, imageName); err == nil {
+			t.Errorf("Expected %s to not be found but it was %v", imageName, image.Names)
+		}
+	}
+}
+
+func TestDecompileFromStorage(t *testing.T) {
+	provider, digest := newTestProvider(t, "vfs")
+	image, err := provider.Decompile(context.Background(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Digest != digest || image.Platform != "linux/amd64" || len(image.LayerSizes) != 1 || image.LayerSizes[0] != 42 {
+		t.Errorf("Expected the digest, platform and layer sizes of the manifest but the image was %+v", image)
+	}
+	if len(image.Names) != 2 || image.Names[1] != "localhost/app:latest" {
+		t.Errorf("Expected the names of the image but they were %v", image.Names)
+	}
+	var instructions []string
+	for _, child := range image.Node.Children {
+		instructions = append(instructions, child.Value)
+	}
+	if !strings.EqualFold(strings.Join(instructions, " "), "expose user") {
+		t.Errorf("Expected the instructions of the history and config but they were %v", instructions)
+	}
+}
+
+func TestMissingStorage(t *testing.T) {
+	provider := StorageProvider{StoreOptions: &storagetypes.StoreOptions{GraphRoot: filepath.Join(t.TempDir(), "missing")}}
+	if _, err := provider.Decompile(context.Background(), "app"); err == nil || !strings.Contains(err.Error(), "no storage found") {
+		t.Errorf("Expected a missing storage error but it was %v", err)
+	}
+	if _, err := os.Stat(provider.StoreOptions.GraphRoot); !os.IsNotExist(err) {
+		t.Errorf("Expected the missing storage not to be created but it was: %v", err)
+	}
+}
+
+func TestEmptyStorage(t *testing.T) {
+	provider := StorageProvider{StoreOptions: &storagetypes.StoreOptions{GraphRoot: t.TempDir(), GraphDriverName: "overlay"}}
+	details, err := provider.Check(context.Background())
+	if err != nil || !strings.HasPrefix(details, "0 images found") {
+		t.Errorf("Expected an empty storage to be usable but it was %s, %v", details, err)
+	}
+}
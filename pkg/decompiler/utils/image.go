@@ -0,0 +1,154 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package utils
+
+import (
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/pkg/errors"
+)
+
+const refNameAnnotation = "org.opencontainers.image.ref.name"
+
+// ImageToNode decompiles the config file of an image
+func ImageToNode(img v1.Image) (*Image, error) {
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	image, err := ConfigFile2Image(configFile)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	image.Digest = digest.String()
+	image.Platform = ConfigPlatform(configFile).String()
+	if manifest, err := img.Manifest(); err == nil {
+		for _, layer := range manifest.Layers {
+			image.LayerSizes = append(image.LayerSizes, layer.Size)
+		}
+	}
+	return image, nil
+}
+
+// SplitReference splits a path[:tag] reference, the tag is empty if not set.
+// As for podman, the path can't contain a colon
+func SplitReference(reference string) (string, string) {
+	if index := strings.Index(reference, ":"); index >= 0 {
+		return reference[:index], reference[index+1:]
+	}
+	return reference, ""
+}
+
+// ImageFromLayout returns the image of the OCI layout at path whose ref.name annotation matches the tag.
+// The tag can be omitted if the layout contains a single manifest. For a multi-architecture index,
+// the image of the platform is selected and the platforms of the index are returned
+func ImageFromLayout(path string, tag string, platform *v1.Platform) (v1.Image, []string, error) {
+	index, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to read the OCI layout %s", path)
+	}
+	return ImageFromIndex(index, path, tag, platform)
+}
+
+// ImageFromIndex is ImageFromLayout for the top-level index of an OCI layout, the name is used in the errors
+func ImageFromIndex(index v1.ImageIndex, name string, tag string, platform *v1.Platform) (v1.Image, []string, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var descriptor *v1.Descriptor
+	for i, desc := range manifest.Manifests {
+		refName := desc.Annotations[refNameAnnotation]
+		if (tag == "" && len(manifest.Manifests) == 1) || (tag != "" && (refName == tag || strings.HasSuffix(refName, ":"+tag))) {
+			descriptor = &manifest.Manifests[i]
+			break
+		}
+	}
+	if descriptor == nil {
+		if tag == "" {
+			return nil, nil, errors.Errorf("%s contains %d manifests, a tag is required", name, len(manifest.Manifests))
+		}
+		return nil, nil, errors.Errorf("unable to find %s in %s", tag, name)
+	}
+
+	if descriptor.MediaType.IsIndex() {
+		child, err := index.ImageIndex(descriptor.Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return SelectPlatformImage(child, platform)
+	}
+	img, err := index.Image(descriptor.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, nil, CheckPlatform(platform, name, ConfigPlatform(configFile))
+}
+
+// SelectPlatformImage returns the image of a multi-architecture index matching the platform and the platforms
+// of the index. If no platform is requested, the default one is selected or the first image if it is missing.
+// The entries which are not images for a known platform, e.g. the attestations of buildkit, are ignored
+func SelectPlatformImage(index v1.ImageIndex, platform *v1.Platform) (v1.Image, []string, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	var platforms []string
+	var images []*v1.Descriptor
+	var selected *v1.Descriptor
+	requested := DefaultPlatform()
+	if platform != nil {
+		requested = *platform
+	}
+	for i, desc := range manifest.Manifests {
+		// attestation manifests have an unknown platform
+		if !desc.MediaType.IsImage() || desc.Platform == nil || desc.Platform.OS == "unknown" {
+			continue
+		}
+		platforms = append(platforms, desc.Platform.String())
+		images = append(images, &manifest.Manifests[i])
+		if selected == nil && MatchPlatform(requested, *desc.Platform) {
+			selected = &manifest.Manifests[i]
+		}
+	}
+	if selected == nil {
+		if platform != nil {
+			return nil, nil, errors.Errorf("no image for platform %s in the index, available platforms: %s", platform.String(), strings.Join(platforms, ", "))
+		}
+		if len(images) == 0 {
+			return nil, nil, errors.New("the image index contains no image for a known platform")
+		}
+		selected = images[0]
+	}
+	img, err := index.Image(selected.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, platforms, nil
+}
@@ -0,0 +1,73 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package utils
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/redhat-developer/docker-openshift-analyzer/internal/testutil"
+)
+
+func TestSelectPlatformImageIgnoresAttestations(t *testing.T) {
+	attestation := testutil.NewImage(t, &v1.ConfigFile{Config: v1.Config{User: "attestation"}})
+	index := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{
+			Add: attestation,
+			Descriptor: v1.Descriptor{
+				MediaType: types.MediaType("application/vnd.in-toto+json"),
+				Platform:  &v1.Platform{OS: "linux", Architecture: "amd64"},
+			},
+		},
+		mutate.IndexAddendum{
+			Add:        attestation,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}},
+		},
+	)
+	index = mutate.AppendManifests(index, mutate.IndexAddendum{
+		Add:        testutil.NewImage(t, &v1.ConfigFile{Architecture: "arm64", Config: v1.Config{User: "1001"}}),
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+	})
+
+	if _, _, err := SelectPlatformImage(index, &v1.Platform{OS: "linux", Architecture: "amd64"}); err == nil || !strings.Contains(err.Error(), "available platforms: linux/arm64") {
+		t.Errorf("Expected an error naming the available platforms but it was %v", err)
+	}
+
+	img, platforms, err := SelectPlatformImage(index, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configFile.Config.User != "1001" || strings.Join(platforms, ",") != "linux/arm64" {
+		t.Errorf("Expected the linux/arm64 image but it was the one of user %s among %v", configFile.Config.User, platforms)
+	}
+
+	if _, _, err := SelectPlatformImage(mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        attestation,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}},
+	}), nil); err == nil {
+		t.Error("Expected an error for an index without image")
+	}
+}
@@ -0,0 +1,56 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package utils
+
+import (
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/pkg/shortnames"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
+)
+
+// ResolveShortName returns the fully qualified candidates of the image name, from the aliases and the
+// unqualified-search registries of registries.conf. All the candidates are returned without prompting, and short
+// names fall back to docker.io when no unqualified-search registry is configured. The default configuration is used
+// if sys is nil
+func ResolveShortName(sys *types.SystemContext, imageName string) ([]reference.Named, error) {
+	ctx := types.SystemContext{}
+	if sys != nil {
+		ctx = *sys
+	}
+	mode := types.ShortNameModeDisabled
+	ctx.ShortNameMode = &mode
+
+	resolved, err := shortnames.Resolve(&ctx, imageName)
+	if err != nil {
+		registries, registriesErr := sysregistriesv2.UnqualifiedSearchRegistries(&ctx)
+		if registriesErr != nil || len(registries) > 0 {
+			return nil, err
+		}
+		named, err := reference.ParseNormalizedNamed(imageName)
+		if err != nil {
+			return nil, err
+		}
+		return []reference.Named{reference.TagNameOnly(named)}, nil
+	}
+	var candidates []reference.Named
+	for _, candidate := range resolved.PullCandidates {
+		candidates = append(candidates, candidate.Value)
+	}
+	return candidates, nil
+}
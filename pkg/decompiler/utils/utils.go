@@ -18,10 +18,18 @@
  package utils
 
 import (
-	"github.com/moby/buildkit/frontend/dockerfile/parser"
-	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
+	"encoding/json"
+	"fmt"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/redhat-developer/docker-openshift-analyzer/pkg/utils"
 )
 
 var CONTAINERFILE_INSTRUCTIONS = []string{
@@ -62,8 +70,12 @@ func Line2Node(line string, root *parser.Node) error {
 
 func parseLabel(line string, root *parser.Node) error {
 	elements := LABEL_PATTERN.FindStringSubmatch(line)
+	if elements == nil {
+		return fmt.Errorf("invalid LABEL instruction %q", line)
+	}
 	parent := &parser.Node{
-		Value: "LABEL",
+		Value:    "LABEL",
+		Original: line,
 	}
 	node := parent
 	for _, element := range elements[1:] {
@@ -76,19 +88,74 @@ func parseLabel(line string, root *parser.Node) error {
 	return nil
 }
 
+var (
+	// BUILD_ARGS_PATTERN matches the build arguments set when running a command, e.g. |2 ARG1=x ARG2=y
+	BUILD_ARGS_PATTERN = regexp.MustCompile(`^\|(\d+)\s+`)
+	BUILD_ARG_PATTERN  = regexp.MustCompile(`^([^\s=]+)=(\S*)\s*`)
+	// WINDOWS_RUN_PATTERN matches the shell running commands in Windows images
+	WINDOWS_RUN_PATTERN = regexp.MustCompile(`(?i)^cmd(?:\.exe)?\s+/S\s+/C\s+`)
+)
+
+// BUILDKIT_SUFFIX is appended by buildkit to the instructions recorded in the history
+const BUILDKIT_SUFFIX = " # buildkit"
+
+// ExtractCmd returns the instruction recorded in a created by history entry, or an empty string if none
 func ExtractCmd(str string) string {
-	index := strings.Index(str, utils.NOP)
-	if index > 0 {
-		return strings.TrimSpace(str[index+len(utils.NOP):])
+	cmd, _ := ParseCreatedBy(str)
+	return cmd
+}
+
+// ParseCreatedBy returns the instruction recorded in a created by history entry and the build arguments
+// it was run with. The entries written by the classic builder (/bin/sh -c #(nop) EXPOSE 80, |1 A=b /bin/sh -c make),
+// by buildkit (RUN |1 A=b /bin/sh -c make # buildkit, COPY . /app # buildkit), by buildah (ENV A=b)
+// and by Windows images (cmd /S /C dir) are supported
+func ParseCreatedBy(str string) (string, map[string]string) {
+	str = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(str), BUILDKIT_SUFFIX))
+	if index := strings.Index(str, utils.NOP); index > 0 {
+		return strings.TrimSpace(str[index+len(utils.NOP):]), nil
+	}
+	buildkitRun := strings.HasPrefix(str, utils.RUN_INSTRUCTION)
+	if buildkitRun {
+		str = strings.TrimSpace(str[len(utils.RUN_INSTRUCTION):])
+	}
+	str, buildArgs := parseBuildArgs(str)
+	if strings.HasPrefix(str, utils.RUN_PREFIX) {
+		return utils.RUN_INSTRUCTION + str[len(utils.RUN_PREFIX):], buildArgs
+	}
+	if match := WINDOWS_RUN_PATTERN.FindString(str); match != "" {
+		return utils.RUN_INSTRUCTION + str[len(match):], buildArgs
+	}
+	if buildkitRun {
+		// exec form or custom shell
+		return utils.RUN_INSTRUCTION + str, buildArgs
 	}
-	index = strings.Index(str, utils.RUN_PREFIX)
-	if index >= 0 {
-		return utils.RUN_INSTRUCTION + str[index+len(utils.RUN_PREFIX):]
+	if index := strings.Index(str, utils.RUN_PREFIX); index >= 0 {
+		return utils.RUN_INSTRUCTION + str[index+len(utils.RUN_PREFIX):], buildArgs
 	}
 	if isContainerFileInstruction(str) {
-		return str
+		return str, buildArgs
 	}
-	return ""
+	return "", nil
+}
+
+// parseBuildArgs strips the |N ARG=value prefix of a command and returns the build arguments it sets
+func parseBuildArgs(str string) (string, map[string]string) {
+	match := BUILD_ARGS_PATTERN.FindStringSubmatch(str)
+	if match == nil {
+		return str, nil
+	}
+	count, _ := strconv.Atoi(match[1])
+	str = str[len(match[0]):]
+	buildArgs := map[string]string{}
+	for i := 0; i < count; i++ {
+		arg := BUILD_ARG_PATTERN.FindStringSubmatch(str)
+		if arg == nil {
+			break
+		}
+		buildArgs[arg[1]] = arg[2]
+		str = str[len(arg[0]):]
+	}
+	return str, buildArgs
 }
 
 func isContainerFileInstruction(str string) bool {
@@ -99,3 +166,355 @@ func isContainerFileInstruction(str string) bool {
 	}
 	return false
 }
+
+// Image is an image decompiled by a provider
+type Image struct {
+	Node *parser.Node
+	// Provider is the name of the provider which found the image
+	Provider string
+	// Digest is the digest of the image manifest, or its ID if the manifest is unknown
+	Digest string
+	// Reference is the fully qualified reference the image was fetched from, if resolved from a registry
+	Reference string
+	// Names are the names the image is tagged with in the local store it was found in, if any
+	Names []string
+	// Platform is the os/arch[/variant] of the image
+	Platform string
+	// Platforms lists the platforms of the index the image was selected from, if it is a multi-architecture image
+	Platforms []string
+	// History maps the instructions rebuilt from the history of the image to their history entry,
+	// the instructions rebuilt from the image config have none
+	History map[*parser.Node]HistoryEntry
+	// LayerSizes are the sizes of the layers of the image, if known. They are compressed if read from a manifest
+	LayerSizes []int64
+	// DiffIDs are the digests of the uncompressed layers of the image, the oldest first, if known
+	DiffIDs []string
+	// HistoryLength is the number of entries of the history of the image
+	HistoryLength int
+}
+
+// HistoryEntry is the entry of the history of an image an instruction was rebuilt from
+type HistoryEntry struct {
+	// Index is the index of the entry in the history of the image config, the oldest being 0
+	Index int
+	// Created is the time the entry was created, if known
+	Created time.Time
+	// CreatedBy is the command recorded in the entry
+	CreatedBy string
+	// EmptyLayer is true if the entry did not create a layer
+	EmptyLayer bool
+	// Layer is the index of the layer created by the entry, -1 if it did not create a layer
+	Layer int
+	// BuildArgs are the build arguments the instruction was run with
+	BuildArgs map[string]string
+}
+
+// DefaultPlatform is the platform selected in a multi-architecture index when none is requested,
+// Linux on the current architecture as the images are run by a Linux host or virtual machine
+func DefaultPlatform() v1.Platform {
+	return v1.Platform{
+		OS:           "linux",
+		Architecture: runtime.GOARCH,
+	}
+}
+
+// MatchPlatform checks a platform satisfies the requested one, the variant is only compared if requested
+func MatchPlatform(requested v1.Platform, platform v1.Platform) bool {
+	return requested.OS == platform.OS && requested.Architecture == platform.Architecture &&
+		(requested.Variant == "" || requested.Variant == platform.Variant)
+}
+
+// ConfigPlatform returns the platform of an image from its config file
+func ConfigPlatform(configFile *v1.ConfigFile) v1.Platform {
+	return v1.Platform{
+		OS:           configFile.OS,
+		Architecture: configFile.Architecture,
+		Variant:      configFile.Variant,
+	}
+}
+
+// CheckPlatform returns an error if an image does not match the requested platform, if any.
+// Images without platform are accepted
+func CheckPlatform(requested *v1.Platform, imageName string, platform v1.Platform) error {
+	if requested != nil && platform.OS != "" && !MatchPlatform(*requested, platform) {
+		return fmt.Errorf("image %s is for %s, not %s", imageName, platform.String(), requested.String())
+	}
+	return nil
+}
+
+// ConfigFile2Image rebuilds the instructions of an image from the history and the runtime configuration of its config file
+func ConfigFile2Image(configFile *v1.ConfigFile) (*Image, error) {
+	root := &parser.Node{}
+	history, err := History2Node(configFile.History, root)
+	if err != nil {
+		return nil, err
+	}
+	if err := Config2Node(configFile.Config, root); err != nil {
+		return nil, err
+	}
+	image := &Image{
+		Node:          root,
+		History:       history,
+		HistoryLength: len(configFile.History),
+	}
+	for _, diffID := range configFile.RootFS.DiffIDs {
+		image.DiffIDs = append(image.DiffIDs, diffID.String())
+	}
+	return image, nil
+}
+
+// History2Node appends the instructions recorded in the history of an image, in the order of the image config,
+// and returns the history entry of each of them
+func History2Node(history []v1.History, root *parser.Node) (map[*parser.Node]HistoryEntry, error) {
+	entries := make([]HistoryEntry, len(history))
+	layer := 0
+	for i, hist := range history {
+		entries[i] = HistoryEntry{
+			Index:      i,
+			Created:    hist.Created.Time,
+			CreatedBy:  hist.CreatedBy,
+			EmptyLayer: hist.EmptyLayer,
+			Layer:      -1,
+		}
+		if !hist.EmptyLayer {
+			entries[i].Layer = layer
+			layer++
+		}
+	}
+	// entries created by the same build step share their timestamp, keep their order.
+	// The config order is kept as is if some entries have no timestamp
+	if createdAll(entries) {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Created.Before(entries[j].Created)
+		})
+	}
+
+	nodes := map[*parser.Node]HistoryEntry{}
+	for _, entry := range entries {
+		var lines []string
+		if comment := history[entry.Index].Comment; comment != "" &&
+			strings.HasPrefix(strings.ToUpper(comment), utils.FROM_INSTRUCTION) && !entry.EmptyLayer {
+			lines = append(lines, comment)
+		}
+		if entry.CreatedBy != "" {
+			cmd, buildArgs := ParseCreatedBy(entry.CreatedBy)
+			entry.BuildArgs = buildArgs
+			if cmd != "" {
+				lines = append(lines, cmd)
+			}
+		}
+		for _, line := range lines {
+			count := len(root.Children)
+			if err := Line2Node(line, root); err != nil {
+				return nil, err
+			}
+			for _, child := range root.Children[count:] {
+				nodes[child] = entry
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func createdAll(entries []HistoryEntry) bool {
+	for _, entry := range entries {
+		if entry.Created.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// Config2Node appends the instructions setting the runtime configuration of an image to its history ones,
+// so that images without reliable history (squashed, built by buildpacks, ko, jib or nix) are analyzed as well.
+// Instructions already found in the history are not appended again, nor the ports, variables and labels it sets
+func Config2Node(config v1.Config, root *parser.Node) error {
+	ports, envs, labels := historyKeys(root)
+	var lines []string
+	for _, port := range sortedKeys(config.ExposedPorts) {
+		if !ports[normalizePort(port)] {
+			lines = append(lines, utils.EXPOSE_INSTRUCTION+port)
+		}
+	}
+	for _, env := range config.Env {
+		if name, value, found := strings.Cut(env, "="); found && !envs[name] {
+			lines = append(lines, utils.ENV_INSTRUCTION+name+"="+quote(value))
+		}
+	}
+	nodes := &parser.Node{}
+	for _, line := range lines {
+		if err := Line2Node(line, nodes); err != nil {
+			return err
+		}
+	}
+	// the labels may contain any character, their instruction is not parsed again
+	for _, name := range sortedKeys(config.Labels) {
+		if labels[name] {
+			continue
+		}
+		nodes.AddChild(labelNode(name, config.Labels[name]), 0, 0)
+	}
+	var runtimeLines []string
+	if len(config.Volumes) > 0 {
+		runtimeLines = append(runtimeLines, utils.VOLUME_INSTRUCTION+toJSON(sortedKeys(config.Volumes)))
+	}
+	if config.WorkingDir != "" {
+		runtimeLines = append(runtimeLines, utils.WORKDIR_INSTRUCTION+config.WorkingDir)
+	}
+	if config.StopSignal != "" {
+		runtimeLines = append(runtimeLines, utils.STOPSIGNAL_INSTRUCTION+config.StopSignal)
+	}
+	if healthcheck := healthcheckLine(config.Healthcheck); healthcheck != "" {
+		runtimeLines = append(runtimeLines, healthcheck)
+	}
+	if config.User != "" {
+		runtimeLines = append(runtimeLines, utils.USER_INSTRUCTION+config.User)
+	}
+	if len(config.Entrypoint) > 0 {
+		runtimeLines = append(runtimeLines, utils.ENTRYPOINT_INSTRUCTION+toJSON(config.Entrypoint))
+	}
+	if len(config.Cmd) > 0 {
+		runtimeLines = append(runtimeLines, utils.CMD_INSTRUCTION+toJSON(config.Cmd))
+	}
+	existing := map[string]bool{}
+	for _, child := range root.Children {
+		existing[nodeKey(child)] = true
+	}
+	for _, line := range runtimeLines {
+		if err := Line2Node(line, nodes); err != nil {
+			return err
+		}
+	}
+	for _, child := range nodes.Children {
+		if !existing[nodeKey(child)] {
+			root.AddChild(child, child.StartLine, child.EndLine)
+		}
+	}
+	return nil
+}
+
+// historyKeys returns the ports exposed, the variables and the labels set by the instructions of the history
+func historyKeys(root *parser.Node) (map[string]bool, map[string]bool, map[string]bool) {
+	ports, envs, labels := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, child := range root.Children {
+		switch strings.ToLower(child.Value) {
+		case "expose":
+			for next := child.Next; next != nil; next = next.Next {
+				ports[normalizePort(next.Value)] = true
+			}
+		case "env", "label":
+			keys := envs
+			if strings.EqualFold(child.Value, "label") {
+				keys = labels
+			}
+			// the arguments are the names and values of the pairs
+			for next := child.Next; next != nil; next = next.Next.Next {
+				keys[unquote(next.Value)] = true
+				if next.Next == nil {
+					break
+				}
+			}
+		}
+	}
+	return ports, envs, labels
+}
+
+// normalizePort returns the port with its protocol, tcp if not set as for EXPOSE
+func normalizePort(port string) string {
+	if !strings.Contains(port, "/") {
+		return port + "/tcp"
+	}
+	return strings.ToLower(port)
+}
+
+// unquote removes the quotes around a word of an instruction, if any
+func unquote(word string) string {
+	if len(word) >= 2 && word[0] == '"' && word[len(word)-1] == '"' {
+		if unquoted, err := strconv.Unquote(word); err == nil {
+			return unquoted
+		}
+	}
+	if len(word) >= 2 && word[0] == '\'' && word[len(word)-1] == '\'' {
+		return word[1 : len(word)-1]
+	}
+	return word
+}
+
+// labelNode returns the LABEL instruction setting a label of the image config
+func labelNode(name string, value string) *parser.Node {
+	return &parser.Node{
+		Value:    "LABEL",
+		Original: utils.LABEL_INSTRUCTION + quote(name) + "=" + quote(value),
+		Next: &parser.Node{
+			Value: name,
+			Next: &parser.Node{
+				Value: value,
+			},
+		},
+	}
+}
+
+// nodeKey identifies an instruction by its name, flags and arguments
+func nodeKey(node *parser.Node) string {
+	key := []string{strings.ToLower(node.Value)}
+	key = append(key, node.Flags...)
+	for next := node.Next; next != nil; next = next.Next {
+		key = append(key, next.Value)
+	}
+	return strings.Join(key, " ")
+}
+
+// healthcheckLine returns the HEALTHCHECK instruction of the health check configuration, if set
+func healthcheckLine(healthcheck *v1.HealthConfig) string {
+	if healthcheck == nil || len(healthcheck.Test) == 0 {
+		return ""
+	}
+	if healthcheck.Test[0] == "NONE" {
+		return utils.HEALTHCHECK_INSTRUCTION + "NONE"
+	}
+	line := utils.HEALTHCHECK_INSTRUCTION
+	for _, option := range []struct {
+		flag     string
+		duration time.Duration
+	}{
+		{"interval", healthcheck.Interval},
+		{"timeout", healthcheck.Timeout},
+		{"start-period", healthcheck.StartPeriod},
+	} {
+		if option.duration > 0 {
+			line += fmt.Sprintf("--%s=%s ", option.flag, option.duration)
+		}
+	}
+	if healthcheck.Retries > 0 {
+		line += fmt.Sprintf("--retries=%d ", healthcheck.Retries)
+	}
+	switch healthcheck.Test[0] {
+	case "CMD":
+		return line + utils.CMD_INSTRUCTION + toJSON(healthcheck.Test[1:])
+	case "CMD-SHELL":
+		return line + utils.CMD_INSTRUCTION + strings.Join(healthcheck.Test[1:], " ")
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toJSON(values []string) string {
+	content, _ := json.Marshal(values)
+	return string(content)
+}
+
+// quote quotes the values which would not be parsed as a single word of a single line
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n=\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,202 @@
+/**********************************************************************
+ * Copyright (C) 2026 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ ***********************************************************************/
+ package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+func TestConfig2Node(t *testing.T) {
+	root := &parser.Node{}
+	err := Config2Node(v1.Config{
+		ExposedPorts: map[string]struct{}{"8080/tcp": {}, "80/tcp": {}},
+		Env:          []string{"PATH=/usr/bin", "GREETING=hello world", "MOTD=line one\nline two"},
+		Labels:       map[string]string{"maintainer": "me", "description": "line one\nline two"},
+		Volumes:      map[string]struct{}{"/data": {}},
+		WorkingDir:   "/app",
+		StopSignal:   "SIGTERM",
+		Healthcheck: &v1.HealthConfig{
+			Test:     []string{"CMD-SHELL", "curl -f http://localhost/"},
+			Interval: 30 * time.Second,
+			Retries:  3,
+		},
+		User:       "root",
+		Entrypoint: []string{"/entrypoint.sh"},
+		Cmd:        []string{"run", "--port", "80"},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"expose", "expose", "env", "env", "env", "label", "label", "volume", "workdir", "stopsignal", "healthcheck", "user", "entrypoint", "cmd"}
+	if len(root.Children) != len(expected) {
+		t.Fatalf("Expected %d instructions but it was %s", len(expected), root.Dump())
+	}
+	for i, child := range root.Children {
+		if !strings.EqualFold(child.Value, expected[i]) {
+			t.Errorf("Expected instruction %d to be %s but it was %s", i, expected[i], child.Value)
+		}
+	}
+	if port := root.Children[0].Next.Value; port != "80/tcp" {
+		t.Errorf("Expected port 80/tcp to be exposed first but it was %s", port)
+	}
+	if value := root.Children[3].Next.Next.Value; value != `"hello world"` {
+		t.Errorf("Expected the environment variable value to be quoted but it was %s", value)
+	}
+	if value := root.Children[4].Next.Next.Value; value != `"line one\nline two"` {
+		t.Errorf("Expected the multi-line environment variable value to be escaped but it was %s", value)
+	}
+	if label := root.Children[5].Next; label.Value != "description" || label.Next.Value != "line one\nline two" {
+		t.Errorf("Expected the multi-line label to be kept but it was %s", root.Children[5].Dump())
+	}
+	if flags := strings.Join(root.Children[10].Flags, " "); flags != "--interval=30s --retries=3" {
+		t.Errorf("Expected the health check options to be kept but they were %s", flags)
+	}
+	if user := root.Children[11].Next.Value; user != "root" {
+		t.Errorf("Expected the user to be root but it was %s", user)
+	}
+}
+
+func TestConfig2NodeSkipsHistoryInstructions(t *testing.T) {
+	root := &parser.Node{}
+	for _, line := range []string{"EXPOSE 80/tcp 443/tcp", "USER 1001", `CMD ["run"]`} {
+		if err := Line2Node(line, root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := Config2Node(v1.Config{
+		ExposedPorts: map[string]struct{}{"80/tcp": {}, "443/tcp": {}, "8443/tcp": {}},
+		User:         "1001",
+		Cmd:          []string{"run"},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 4 || root.Children[3].Next.Value != "8443/tcp" {
+		t.Errorf("Expected only the port 8443/tcp to be added but it was %s", root.Dump())
+	}
+}
+
+func TestConfig2NodeSkipsHistoryKeys(t *testing.T) {
+	root := &parser.Node{}
+	for _, line := range []string{"EXPOSE 80 443/UDP", `ENV GREETING="hello world" PATH=/bin`, `LABEL "description"="a b"`} {
+		if err := Line2Node(line, root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := Config2Node(v1.Config{
+		ExposedPorts: map[string]struct{}{"80/tcp": {}, "443/udp": {}, "8443/tcp": {}},
+		Env:          []string{"GREETING=hello world", "PATH=/usr/bin:/bin", "HOME=/app"},
+		Labels:       map[string]string{"description": "a b", "maintainer": "me"},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added []string
+	for _, child := range root.Children[3:] {
+		added = append(added, nodeKey(child))
+	}
+	if strings.Join(added, ",") != "expose 8443/tcp,env HOME /app,label maintainer me" {
+		t.Errorf("Expected only the missing port, variable and label to be added but they were %v", added)
+	}
+}
+
+func TestParseCreatedBy(t *testing.T) {
+	for _, test := range []struct {
+		createdBy string
+		cmd       string
+		buildArgs map[string]string
+	}{
+		{`/bin/sh -c #(nop)  EXPOSE 80/tcp`, "EXPOSE 80/tcp", nil},
+		{`/bin/sh -c chmod 700 /app`, "RUN chmod 700 /app", nil},
+		{`|2 VERSION=1.0 USER=app /bin/sh -c chown $USER /app`, "RUN chown $USER /app", map[string]string{"VERSION": "1.0", "USER": "app"}},
+		{`RUN |1 VERSION=1.0 /bin/sh -c make install # buildkit`, "RUN make install", map[string]string{"VERSION": "1.0"}},
+		{`RUN /bin/sh -c sudo make # buildkit`, "RUN sudo make", nil},
+		{`RUN make install # buildkit`, "RUN make install", nil},
+		{`COPY . /app # buildkit`, "COPY . /app", nil},
+		{`ENV A=b`, "ENV A=b", nil},
+		{`WORKDIR /app`, "WORKDIR /app", nil},
+		{`cmd /S /C powershell -Command Install-Package`, "RUN powershell -Command Install-Package", nil},
+		{`cmd /S /C #(nop)  USER ContainerUser`, "USER ContainerUser", nil},
+		{`sha256:0123456789abcdef`, "", nil},
+	} {
+		cmd, buildArgs := ParseCreatedBy(test.createdBy)
+		if cmd != test.cmd {
+			t.Errorf("Expected %s to be parsed as %s but it was %s", test.createdBy, test.cmd, cmd)
+		}
+		if len(buildArgs) != len(test.buildArgs) {
+			t.Errorf("Expected %s to set the build arguments %v but it was %v", test.createdBy, test.buildArgs, buildArgs)
+		}
+		for name, value := range test.buildArgs {
+			if buildArgs[name] != value {
+				t.Errorf("Expected %s to set %s=%s but it was %s", test.createdBy, name, value, buildArgs[name])
+			}
+		}
+	}
+}
+
+func TestHistory2Node(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	root := &parser.Node{}
+	history, err := History2Node([]v1.History{
+		{Created: v1.Time{Time: created}, CreatedBy: `/bin/sh -c #(nop) ADD file:abc in / `},
+		{Created: v1.Time{Time: created.Add(time.Hour)}, CreatedBy: `RUN |1 VERSION=1.0 /bin/sh -c make # buildkit`},
+		{Created: v1.Time{Time: created.Add(time.Hour)}, CreatedBy: `USER 1001`, EmptyLayer: true},
+		{Created: v1.Time{Time: created.Add(time.Hour)}, CreatedBy: `EXPOSE 8080/tcp`, EmptyLayer: true},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"add", "run", "user", "expose"}
+	if len(root.Children) != len(expected) {
+		t.Fatalf("Expected %d instructions but it was %s", len(expected), root.Dump())
+	}
+	for i, child := range root.Children {
+		if !strings.EqualFold(child.Value, expected[i]) {
+			t.Errorf("Expected instruction %d to be %s but it was %s", i, expected[i], child.Value)
+		}
+		if entry := history[child]; entry.Index != i {
+			t.Errorf("Expected instruction %d to be rebuilt from history entry %d but it was %d", i, i, entry.Index)
+		}
+	}
+	run := history[root.Children[1]]
+	if !run.Created.Equal(created.Add(time.Hour)) || run.BuildArgs["VERSION"] != "1.0" {
+		t.Errorf("Expected the creation time and build arguments of the RUN instruction to be kept but it was %+v", run)
+	}
+	if !history[root.Children[2]].EmptyLayer {
+		t.Error("Expected the USER instruction not to create a layer")
+	}
+}
+
+func TestHistory2NodeWithoutTimestamps(t *testing.T) {
+	root := &parser.Node{}
+	_, err := History2Node([]v1.History{
+		{Created: v1.Time{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, CreatedBy: `RUN /bin/sh -c make # buildkit`},
+		{CreatedBy: `USER 1001`, EmptyLayer: true},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 2 || !strings.EqualFold(root.Children[1].Value, "user") {
+		t.Errorf("Expected the history order to be kept but it was %s", root.Dump())
+	}
+}
@@ -21,6 +21,10 @@ const NOP = "#(nop) "
 
 const RUN_PREFIX = "/bin/sh -c "
 
+const OCI_LAYOUT_TRANSPORT = "oci:"
+const OCI_ARCHIVE_TRANSPORT = "oci-archive:"
+const DOCKER_ARCHIVE_TRANSPORT = "docker-archive:"
+
 const FROM_INSTRUCTION = "FROM "
 const RUN_INSTRUCTION = "RUN "
 const CMD_INSTRUCTION = "CMD "